@@ -9,7 +9,7 @@ import (
 )
 
 func ConnectDatabase(config *Environment) *gorm.DB {
-	credentials := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", config.host, config.port, config.username, config.password, config.database)
+	credentials := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", config.DatabaseHost, config.DatabasePort, config.DatabaseUser, config.DatabasePassword, config.DatabaseName)
 
 	db, err := gorm.Open(postgres.Open(credentials), &gorm.Config{})
 	helper.PanicIfError(err)
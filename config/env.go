@@ -1,34 +1,127 @@
 package config
 
 import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
 )
 
+// Environment is the configuration of the service. Every field is set from
+// the key of its env tag, see Load for the sources, and falls back to its
+// default tag. secret fields are redacted when the configuration is
+// printed.
 type Environment struct {
-	host       string `mapstructure:"DATABASE_HOST"`
-	port       string `mapstructure:"DATABASE_PORT"`
-	database   string `mapstructure:"DATABASE_NAME"`
-	username   string `mapstructure:"DATABASE_USER"`
-	password   string `mapstructure:"DATABASE_PASSWORD"`
-	jwt_secret string `mapstructure:"JWT_SECRET"`
+	AppAddress        string   `env:"APP_ADDRESS" default:"127.0.0.1:3000" validate:"hostname_port"`
+	CORSAllowOrigins  []string `env:"CORS_ALLOW_ORIGINS" default:"http://localhost:5173" validate:"dive,url|eq=*"`
+	LogLevel          string   `env:"LOG_LEVEL" default:"info" validate:"oneof=trace debug info warn error fatal panic"`
+	LogFile           string   `env:"LOG_FILE" default:"app.log" validate:"required"`
+	DiscordWebhookURL string   `env:"DISCORD_WEBHOOK_URL" secret:"true" validate:"omitempty,url"`
+
+	DatabaseHost     string `env:"DATABASE_HOST" default:"127.0.0.1" validate:"required"`
+	DatabasePort     int    `env:"DATABASE_PORT" default:"5432" validate:"min=1,max=65535"`
+	DatabaseName     string `env:"DATABASE_NAME" validate:"required"`
+	DatabaseUser     string `env:"DATABASE_USER" validate:"required"`
+	DatabasePassword string `env:"DATABASE_PASSWORD" secret:"true"`
+
+	JWTSecret             string `env:"JWT_SECRET" secret:"true" validate:"required_without=JWTKeysDir"`
+	JWTRefreshSecret      string `env:"JWT_REFRESH_SECRET" secret:"true" validate:"required,nefield=JWTSecret"`
+	JWTVerificationSecret string `env:"JWT_VERIFICATION_SECRET" secret:"true" validate:"required"`
+	JWTMfaSecret          string `env:"JWT_MFA_SECRET" secret:"true" validate:"required"`
+	JWTMagicLinkSecret    string `env:"JWT_MAGIC_LINK_SECRET" secret:"true" validate:"required"`
+	JWTKeysDir            string `env:"JWT_KEYS_DIR" validate:"omitempty,dir"`
+	JWTActiveKeyId        string `env:"JWT_ACTIVE_KEY_ID"`
+
+	AccessTokenTTL       time.Duration `env:"ACCESS_TOKEN_TTL" default:"15m" validate:"min=1m"`
+	RefreshTokenTTL      time.Duration `env:"REFRESH_TOKEN_TTL" default:"168h" validate:"gtfield=AccessTokenTTL"`
+	EmailVerificationTTL time.Duration `env:"EMAIL_VERIFICATION_TTL" default:"24h" validate:"min=1m"`
+	MfaChallengeTTL      time.Duration `env:"MFA_CHALLENGE_TTL" default:"5m" validate:"min=1m"`
+	MagicLinkTTL         time.Duration `env:"MAGIC_LINK_TTL" default:"15m" validate:"min=1m"`
+	ImpersonationTTL     time.Duration `env:"IMPERSONATION_TTL" default:"15m" validate:"min=1m"`
+
+	FrontendEndpoint     string `env:"FRONTEND_ENDPOINT" validate:"omitempty,url"`
+	RequireVerifiedEmail bool   `env:"AUTH_REQUIRE_VERIFIED_EMAIL"`
+	AdminEmail           string `env:"ADMIN_EMAIL" validate:"omitempty,email"`
+	MfaIssuer            string `env:"MFA_ISSUER"`
+	OIDCIssuer           string `env:"OIDC_ISSUER" validate:"omitempty,url"`
+	CSRFSecret           string `env:"CSRF_SECRET" secret:"true"`
+
+	TrustedProxies []string `env:"TRUSTED_PROXIES" validate:"dive,cidr|ip"`
+	ClientIPHeader string   `env:"CLIENT_IP_HEADER" validate:"omitempty,oneof=X-Forwarded-For X-Real-IP Forwarded"`
+
+	LoginThrottleFreeAttempts  int           `env:"LOGIN_THROTTLE_FREE_ATTEMPTS" validate:"min=0"`
+	LoginThrottleBaseDelay     time.Duration `env:"LOGIN_THROTTLE_BASE_DELAY" validate:"min=0"`
+	LoginThrottleMaxDelay      time.Duration `env:"LOGIN_THROTTLE_MAX_DELAY" validate:"omitempty,gtefield=LoginThrottleBaseDelay"`
+	LoginThrottleMaxFailures   int           `env:"LOGIN_THROTTLE_MAX_FAILURES" validate:"min=0"`
+	LoginLockoutDuration       time.Duration `env:"LOGIN_LOCKOUT_DURATION" validate:"min=0"`
+	LoginThrottleWindow        time.Duration `env:"LOGIN_THROTTLE_WINDOW" validate:"min=0"`
+	LoginThrottlePruneInterval time.Duration `env:"LOGIN_THROTTLE_PRUNE_INTERVAL"`
+	MfaChallengeMaxFailures    int           `env:"MFA_CHALLENGE_MAX_FAILURES" validate:"min=0"`
+
+	MailDriver       string `env:"MAIL_DRIVER" validate:"omitempty,oneof=log file smtp"`
+	MailFrom         string `env:"MAIL_FROM"`
+	MailPath         string `env:"MAIL_PATH"`
+	MailSMTPHost     string `env:"MAIL_SMTP_HOST" validate:"required_if=MailDriver smtp"`
+	MailSMTPPort     string `env:"MAIL_SMTP_PORT" validate:"omitempty,number"`
+	MailSMTPUsername string `env:"MAIL_SMTP_USERNAME"`
+	MailSMTPPassword string `env:"MAIL_SMTP_PASSWORD" secret:"true"`
+
+	TokenStoreDriver string `env:"TOKEN_STORE" validate:"omitempty,oneof=memory postgres redis"`
+	RedisURL         string `env:"REDIS_URL" secret:"true" validate:"required_if=TokenStoreDriver redis"`
+
+	PasswordHasher    string `env:"PASSWORD_HASHER" validate:"omitempty,oneof=argon2id bcrypt"`
+	BcryptCost        int    `env:"BCRYPT_COST" validate:"omitempty,min=4,max=31"`
+	Argon2Memory      uint32 `env:"ARGON2_MEMORY"`
+	Argon2Iterations  uint32 `env:"ARGON2_ITERATIONS"`
+	Argon2Parallelism uint8  `env:"ARGON2_PARALLELISM"`
+
+	PasswordWorkers      int           `env:"PASSWORD_WORKERS" validate:"min=0"`
+	PasswordQueueSize    int           `env:"PASSWORD_QUEUE_SIZE" validate:"min=0"`
+	PasswordQueueTimeout time.Duration `env:"PASSWORD_QUEUE_TIMEOUT" validate:"min=0"`
+
+	PasswordMinLength      int    `env:"PASSWORD_MIN_LENGTH" validate:"min=0"`
+	PasswordMaxLength      int    `env:"PASSWORD_MAX_LENGTH" validate:"omitempty,gtefield=PasswordMinLength"`
+	PasswordRequireUpper   bool   `env:"PASSWORD_REQUIRE_UPPER"`
+	PasswordRequireLower   bool   `env:"PASSWORD_REQUIRE_LOWER"`
+	PasswordRequireDigit   bool   `env:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireSymbol  bool   `env:"PASSWORD_REQUIRE_SYMBOL"`
+	PasswordDenyContext    bool   `env:"PASSWORD_DENY_CONTEXT"`
+	PasswordHistory        int    `env:"PASSWORD_HISTORY" validate:"min=0"`
+	PasswordBreachCorpus   string `env:"PASSWORD_BREACH_CORPUS"`
+	PasswordBreachMinCount int    `env:"PASSWORD_BREACH_MIN_COUNT" validate:"min=0"`
+
+	GeoIPDatabase       string        `env:"GEOIP_DATABASE" validate:"omitempty,file"`
+	GeoIPCacheSize      int           `env:"GEOIP_CACHE_SIZE" validate:"min=0"`
+	GeoIPReloadInterval time.Duration `env:"GEOIP_RELOAD_INTERVAL"`
 }
 
-func DotEnv() (env Environment, err error) {
-	err = godotenv.Load()
+const redacted = "[REDACTED]"
+
+// String lists the configuration as KEY=value lines, with the values of
+// secret keys redacted.
+func (env Environment) String() string {
+	var b strings.Builder
 
-	if err != nil {
-		log.Fatal("Error loading .env file")
+	value := reflect.ValueOf(env)
+	for _, field := range fields() {
+		text := format(value.Field(field.index))
+		if field.secret && text != "" {
+			text = redacted
+		}
+		fmt.Fprintf(&b, "%s=%s\n", field.key, text)
 	}
 
-	env.host = os.Getenv("DATABASE_HOST")
-	env.port = os.Getenv("DATABASE_PORT")
-	env.database = os.Getenv("DATABASE_NAME")
-	env.username = os.Getenv("DATABASE_USER")
-	env.password = os.Getenv("DATABASE_PASSWORD")
-	env.jwt_secret = os.Getenv("JWT_SECRET")
+	return b.String()
+}
+
+// GoString keeps %#v from printing the secrets.
+func (env Environment) GoString() string {
+	return env.String()
+}
 
-	return
+func format(value reflect.Value) string {
+	if list, ok := value.Interface().([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(value.Interface())
 }
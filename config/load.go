@@ -0,0 +1,264 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// field describes a key of Environment.
+type field struct {
+	index    int
+	key      string
+	fallback string
+	secret   bool
+}
+
+func fields() []field {
+	t := reflect.TypeOf(Environment{})
+
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+		fields = append(fields, field{
+			index:    i,
+			key:      tag.Get("env"),
+			fallback: tag.Get("default"),
+			secret:   tag.Get("secret") == "true",
+		})
+	}
+
+	return fields
+}
+
+// Load builds the configuration from, in increasing order of precedence:
+// the defaults of Environment, the YAML or TOML file named by the -config
+// flag or CONFIG_FILE, set in the environment or the .env file, the .env
+// file (-env-file), the environment
+// variables and the command line flags, one per key (--database-host for
+// DATABASE_HOST). Empty values are ignored, so they never clear a value of
+// a lower source.
+//
+// Every value that cannot be parsed and every failed validation is
+// reported, joined into the returned error.
+func Load(args []string) (Environment, error) {
+	var env Environment
+	fields := fields()
+
+	values := map[string]string{}
+	for _, field := range fields {
+		if field.fallback != "" {
+			values[field.key] = field.fallback
+		}
+	}
+
+	flagSet := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFile := flagSet.String("config", "", "YAML or TOML configuration file, defaults to CONFIG_FILE")
+	envFile := flagSet.String("env-file", ".env", "dotenv file, ignored when missing")
+	flags := map[string]string{}
+	for _, field := range fields {
+		key := field.key
+		flagSet.Func(strings.ReplaceAll(strings.ToLower(key), "_", "-"), "sets "+key, func(value string) error {
+			flags[key] = value
+			return nil
+		})
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return env, err
+	}
+
+	var errs []error
+	merge := func(source map[string]string) {
+		for key, value := range source {
+			if value != "" {
+				values[key] = value
+			}
+		}
+	}
+
+	// the .env file is read first, it may name the configuration file
+	dotenv, err := godotenv.Read(*envFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("%s: %w", *envFile, err))
+	}
+
+	file := *configFile
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file == "" {
+		file = dotenv["CONFIG_FILE"]
+	}
+	if file != "" {
+		source, err := readFile(file, fields)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		merge(source)
+	}
+
+	merge(only(dotenv, fields))
+
+	environ := map[string]string{}
+	for _, field := range fields {
+		environ[field.key] = os.Getenv(field.key)
+	}
+	merge(environ)
+	merge(flags)
+
+	failed := map[string]bool{}
+	value := reflect.ValueOf(&env).Elem()
+	for _, field := range fields {
+		text, ok := values[field.key]
+		if !ok {
+			continue
+		}
+		if err := assign(value.Field(field.index), text); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.key, err))
+			failed[field.key] = true
+		}
+	}
+
+	errs = append(errs, check(env, failed)...)
+
+	return env, errors.Join(errs...)
+}
+
+// only keeps the keys of the configuration, a .env file is free to hold
+// other variables.
+func only(source map[string]string, fields []field) map[string]string {
+	values := map[string]string{}
+	for _, field := range fields {
+		if value, ok := source[field.key]; ok {
+			values[field.key] = value
+		}
+	}
+	return values
+}
+
+// readFile reads a YAML or TOML file. Nested tables are joined with an
+// underscore and matched regardless of case, so database: {host: x} sets
+// DATABASE_HOST just like DATABASE_HOST: x. Lists become comma separated
+// values.
+func readFile(path string, fields []field) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("%s: unsupported configuration file, expected .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, field := range fields {
+		known[field.key] = true
+	}
+
+	values := map[string]string{}
+	var unknown []string
+	var flatten func(prefix string, document map[string]any)
+	flatten = func(prefix string, document map[string]any) {
+		for name, value := range document {
+			key := strings.ToUpper(prefix + name)
+			if table, ok := value.(map[string]any); ok {
+				flatten(key+"_", table)
+				continue
+			}
+			if !known[key] {
+				unknown = append(unknown, key)
+				continue
+			}
+			values[key] = scalar(value)
+		}
+	}
+	flatten("", document)
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return values, fmt.Errorf("%s: unknown keys %s", path, strings.Join(unknown, ", "))
+	}
+
+	return values, nil
+}
+
+func scalar(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, scalar(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func assign(value reflect.Value, text string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return errors.New("must be a duration such as 15m or 1h")
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		i, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		value.SetInt(i)
+	case reflect.Uint8, reflect.Uint32:
+		u, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer between 0 and %d", uint64(1)<<value.Type().Bits()-1)
+		}
+		value.SetUint(u)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// check runs the validate tags of Environment and returns an error per
+// failed key. Keys that could not be parsed are skipped, they have been
+// reported already.
+func check(env Environment, failed map[string]bool) []error {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("env")
+	})
+
+	err := validate.Struct(env)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, fieldError := range validationErrors {
+		key := fieldError.Field()
+		if index := strings.IndexByte(key, '['); index >= 0 && failed[key[:index]] || failed[key] {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", key, describe(fieldError)))
+	}
+
+	return errs
+}
+
+func describe(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return "is required when " + key(strings.Fields(param)[0]) + " is " + strings.Fields(param)[1]
+	case "required_without":
+		return "is required when " + key(param) + " is not set"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(param, " ", ", ")
+	case "min", "gte":
+		return "must be at least " + param
+	case "max", "lte":
+		return "must be at most " + param
+	case "gtfield":
+		return "must be greater than " + key(param)
+	case "gtefield":
+		return "must be at least " + key(param)
+	case "nefield":
+		return "must differ from " + key(param)
+	case "url", "url|eq=*":
+		return "must be a URL"
+	case "email":
+		return "must be an email address"
+	case "number":
+		return "must be a number"
+	case "hostname_port":
+		return "must be a host:port address"
+	case "cidr|ip":
+		return "must be an IP address or a CIDR"
+	case "dir":
+		return "must be an existing directory"
+	case "file":
+		return "must be an existing file"
+	default:
+		return "failed the " + fieldError.Tag() + " check"
+	}
+}
+
+// key returns the env key of the Environment field name, as referenced by
+// the cross-field tags.
+func key(name string) string {
+	if field, ok := reflect.TypeOf(Environment{}).FieldByName(name); ok {
+		return field.Tag.Get("env")
+	}
+	return name
+}
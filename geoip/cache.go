@@ -0,0 +1,66 @@
+package geoip
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cache keeps the most recently used locations.
+type cache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	ip       string
+	location Location
+}
+
+func newCache(size int) *cache {
+	return &cache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *cache) get(ip string) (Location, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[ip]
+	if !ok {
+		return Location{}, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).location, true
+}
+
+func (c *cache) add(ip string, location Location) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[ip]; ok {
+		element.Value.(*cacheEntry).location = location
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[ip] = c.order.PushFront(&cacheEntry{ip: ip, location: location})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).ip)
+	}
+}
+
+func (c *cache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[string]*list.Element, c.size)
+}
@@ -0,0 +1,199 @@
+// Package geoip resolves IP addresses to an approximate location using a
+// local MaxMind database (GeoLite2 or GeoIP2 City or Country). Nothing is
+// sent over the network.
+package geoip
+
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const (
+	DefaultCacheSize      = 4096
+	DefaultReloadInterval = time.Minute
+)
+
+// Location is the approximate place of an IP address. Country is the
+// ISO 3166-1 alpha-2 code and City the English name; either is empty when
+// the database does not know it.
+type Location struct {
+	Country string `json:"country,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+// String formats the location as "City, Country", or returns an empty
+// string for an unknown location.
+func (l Location) String() string {
+	switch {
+	case l.City != "" && l.Country != "":
+		return l.City + ", " + l.Country
+	case l.Country != "":
+		return l.Country
+	default:
+		return l.City
+	}
+}
+
+// Resolver looks up the location of an IP address. Unknown, private and
+// malformed addresses yield an empty Location.
+type Resolver interface {
+	Lookup(ip string) Location
+}
+
+// Config configures a Database. Zero values fall back to the defaults.
+type Config struct {
+	// Path of the .mmdb file.
+	Path string
+	// CacheSize is the number of addresses whose location is kept in
+	// memory.
+	CacheSize int
+	// ReloadInterval is how often the file is checked for changes. A
+	// negative value disables reloading.
+	ReloadInterval time.Duration
+}
+
+// Database is a Resolver backed by a MaxMind database file. The file is
+// reopened when its size or modification time changes, so it can be
+// updated in place, preferably by renaming the new file over the old one.
+type Database struct {
+	config Config
+	cache  *cache
+
+	mu      sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+	size    int64
+
+	stop chan struct{}
+	once sync.Once
+}
+
+type record struct {
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// Open opens the database and starts watching the file for changes.
+func Open(config Config) (*Database, error) {
+	if config.CacheSize <= 0 {
+		config.CacheSize = DefaultCacheSize
+	}
+	if config.ReloadInterval == 0 {
+		config.ReloadInterval = DefaultReloadInterval
+	}
+
+	d := &Database{
+		config: config,
+		cache:  newCache(config.CacheSize),
+		stop:   make(chan struct{}),
+	}
+
+	if _, err := d.Reload(); err != nil {
+		return nil, err
+	}
+
+	if config.ReloadInterval > 0 {
+		go d.watch()
+	}
+
+	return d, nil
+}
+
+// Lookup implements Resolver.
+func (d *Database) Lookup(ip string) Location {
+	if location, ok := d.cache.get(ip); ok {
+		return location
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}
+	}
+
+	// the read lock covers adding to the cache, so a result of the old
+	// file cannot land in the cache after a reload purged it
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var rec record
+	if err := d.reader.Lookup(parsed, &rec); err != nil {
+		logger.GetLogger().WithError(err).Error("geoip lookup failed: " + ip)
+		return Location{}
+	}
+
+	location := Location{Country: rec.Country.IsoCode, City: rec.City.Names["en"]}
+	d.cache.add(ip, location)
+
+	return location
+}
+
+// Reload reopens the file when it changed since it was last opened and
+// reports whether it did. On error the current database stays in use.
+func (d *Database) Reload() (bool, error) {
+	info, err := os.Stat(d.config.Path)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.RLock()
+	unchanged := d.reader != nil && info.ModTime().Equal(d.modTime) && info.Size() == d.size
+	d.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	reader, err := maxminddb.Open(d.config.Path)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.Lock()
+	old := d.reader
+	d.reader, d.modTime, d.size = reader, info.ModTime(), info.Size()
+	d.cache.purge()
+	d.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+
+	return true, nil
+}
+
+// Close stops watching the file and closes the database.
+func (d *Database) Close() error {
+	d.once.Do(func() { close(d.stop) })
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reader.Close()
+}
+
+func (d *Database) watch() {
+	ticker := time.NewTicker(d.config.ReloadInterval)
+	defer ticker.Stop()
+
+	log := logger.GetLogger()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			reloaded, err := d.Reload()
+			if err != nil {
+				log.WithError(err).Error("failed to reload geoip database: " + d.config.Path)
+			} else if reloaded {
+				log.Info("reloaded geoip database: " + d.config.Path)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package helper
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IPNetwork returns the /24 range of an IPv4 address or the /48 range of
+// an IPv6 address in CIDR notation, or an empty string when ip does not
+// parse. Addresses in the same range usually belong to the same provider
+// and area.
+func IPNetwork(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
+
+// Headers a reverse proxy can pass the client address in.
+const (
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-IP"
+	HeaderForwarded     = "Forwarded"
+)
+
+// TrustedProxies resolves the address of the client behind reverse
+// proxies. The header is only believed when the request comes from a
+// trusted proxy, and list headers are read from the right, skipping the
+// trusted proxies that appended to them, so entries added by the client
+// itself are never used.
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+	header   string
+}
+
+// NewTrustedProxies parses the trusted proxies, given as CIDRs or single
+// addresses. header is one of X-Forwarded-For (the default), X-Real-IP and
+// Forwarded (RFC 7239).
+func NewTrustedProxies(cidrs []string, header string) (*TrustedProxies, error) {
+	p := &TrustedProxies{header: HeaderXForwardedFor}
+
+	switch {
+	case header == "":
+	case strings.EqualFold(header, HeaderXForwardedFor):
+		p.header = HeaderXForwardedFor
+	case strings.EqualFold(header, HeaderXRealIP):
+		p.header = HeaderXRealIP
+	case strings.EqualFold(header, HeaderForwarded):
+		p.header = HeaderForwarded
+	default:
+		return nil, fmt.Errorf("unsupported client ip header: %s", header)
+	}
+
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+			}
+			addr = addr.Unmap()
+			p.prefixes = append(p.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		p.prefixes = append(p.prefixes, prefix.Masked())
+	}
+
+	return p, nil
+}
+
+// ClientIP returns the address of the client of the request.
+func (p *TrustedProxies) ClientIP(ctx *fiber.Ctx) string {
+	remote, ok := netip.AddrFromSlice(ctx.Context().RemoteIP())
+	if !ok {
+		return ctx.Context().RemoteIP().String()
+	}
+	remote = remote.Unmap()
+
+	if !p.isTrusted(remote) {
+		return remote.String()
+	}
+
+	var hops []string
+	switch p.header {
+	case HeaderXRealIP:
+		hops = []string{ctx.Get(HeaderXRealIP)}
+	case HeaderForwarded:
+		hops = forwardedFor(ctx.Request().Header.PeekAll(HeaderForwarded))
+	default:
+		for _, value := range ctx.Request().Header.PeekAll(HeaderXForwardedFor) {
+			hops = append(hops, strings.Split(string(value), ",")...)
+		}
+	}
+
+	// walk back from the proxy that connected to us until the first
+	// address that is not one of our proxies
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHop(hops[i])
+		if !ok {
+			break
+		}
+		client = addr
+		if !p.isTrusted(addr) {
+			break
+		}
+	}
+
+	return client.String()
+}
+
+func (p *TrustedProxies) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the for= values of RFC 7239 Forwarded headers in
+// order.
+func forwardedFor(values [][]byte) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(string(value), ",") {
+			for _, pair := range strings.Split(element, ";") {
+				name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(name, "for") {
+					hops = append(hops, value)
+				}
+			}
+		}
+	}
+	return hops
+}
+
+// parseHop parses one address of a forwarding header. Quotes, IPv6
+// brackets and ports are accepted; obfuscated identifiers and "unknown"
+// are not.
+func parseHop(hop string) (netip.Addr, bool) {
+	hop = strings.Trim(strings.TrimSpace(hop), `"`)
+
+	if addrPort, err := netip.ParseAddrPort(hop); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// trustedProxies resolves client addresses once UseTrustedProxies has been
+// called; until then the address of the connection is used.
+var trustedProxies *TrustedProxies
+
+// UseTrustedProxies configures how ClientIP reads the client address. It
+// must be called before the server starts handling requests.
+func UseTrustedProxies(proxies *TrustedProxies) {
+	trustedProxies = proxies
+}
+
+// ClientIP returns the address of the client of the request, looking
+// through the trusted proxies. Use it instead of ctx.IP(), so logs,
+// throttling and sessions agree on the address.
+func ClientIP(ctx *fiber.Ctx) string {
+	if trustedProxies == nil {
+		return ctx.IP()
+	}
+	return trustedProxies.ClientIP(ctx)
+}
@@ -2,50 +2,395 @@ package helper
 
 import (
 	"fmt"
-	"os"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/fatihrizqon/go-fiber-service/internal/entity"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
-var refreshSecret = []byte(os.Getenv("JWT_REFRESH_SECRET"))
+func init() {
+	// iat is compared with the time a user's tokens were revoked, with
+	// whole seconds a sign-in right after a revocation would be rejected
+	jwt.TimePrecision = time.Millisecond
+}
+
+var refreshSecret []byte
+var verificationSecret []byte
+var mfaSecret []byte
+var magicLinkSecret []byte
+
+// accessKeys signs and verifies access tokens. It is HS256 with the access
+// secret of UseTokenConfig until UseAccessKeySet installs asymmetric keys.
+var accessKeys = NewHMACKeySet(nil)
+
+// TokenConfig holds the secrets and lifetimes of the tokens. Lifetimes
+// left zero keep their defaults.
+type TokenConfig struct {
+	AccessSecret       []byte
+	RefreshSecret      []byte
+	VerificationSecret []byte
+	MfaSecret          []byte
+	MagicLinkSecret    []byte
+
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	EmailVerificationTTL time.Duration
+	MfaChallengeTTL      time.Duration
+	MagicLinkTTL         time.Duration
+	ImpersonationTTL     time.Duration
+}
+
+// UseTokenConfig installs the secrets and lifetimes of the tokens. It must
+// be called before the server starts handling requests, and before
+// UseAccessKeySet as it resets the access keys to the access secret.
+func UseTokenConfig(config TokenConfig) {
+	accessKeys = NewHMACKeySet(config.AccessSecret)
+	refreshSecret = config.RefreshSecret
+	verificationSecret = config.VerificationSecret
+	mfaSecret = config.MfaSecret
+	magicLinkSecret = config.MagicLinkSecret
+
+	for _, ttl := range []struct {
+		value  time.Duration
+		target *time.Duration
+	}{
+		{config.AccessTokenTTL, &AccessTokenTTL},
+		{config.RefreshTokenTTL, &RefreshTokenTTL},
+		{config.EmailVerificationTTL, &EmailVerificationTTL},
+		{config.MfaChallengeTTL, &MfaChallengeTTL},
+		{config.MagicLinkTTL, &MagicLinkTTL},
+		{config.ImpersonationTTL, &ImpersonationTTL},
+	} {
+		if ttl.value > 0 {
+			*ttl.target = ttl.value
+		}
+	}
+}
+
+// UseAccessKeySet replaces the keys of access tokens. It must be called
+// before the server starts handling requests.
+func UseAccessKeySet(keys *KeySet) {
+	accessKeys = keys
+}
+
+// AccessKeySet returns the keys of access tokens.
+func AccessKeySet() *KeySet {
+	return accessKeys
+}
+
+// Token types of the typ claim, so an access token is never accepted as a
+// refresh token and the other way around, even when both share a key.
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+const (
+	purposeEmailVerification = "email_verification"
+	purposeMfaChallenge      = "mfa_challenge"
+	purposeMagicLink         = "magic_link"
+)
+
+// Lifetimes of the tokens, set through UseTokenConfig.
+var (
+	AccessTokenTTL       = 15 * time.Minute
+	RefreshTokenTTL      = 7 * 24 * time.Hour
+	EmailVerificationTTL = 24 * time.Hour
+	MfaChallengeTTL      = 5 * time.Minute
+	MagicLinkTTL         = 15 * time.Minute
+	// ImpersonationTTL bounds an impersonation, its tokens cannot be refreshed
+	ImpersonationTTL = 15 * time.Minute
+)
+
+// Claims are the claims carried by access and refresh tokens. SessionId is
+// only set on access tokens and Family only on refresh tokens, both hold
+// the id of the session the token belongs to. ClientId and Scope are set
+// on tokens issued through the OAuth endpoints; tokens of the
+// client_credentials grant have a ClientId but no user Id. Actor is set on
+// impersonation tokens and names the admin acting as the user. Type tells
+// access and refresh tokens apart.
+type Claims struct {
+	Type        string   `json:"typ"`
+	Id          string   `json:"id,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	SessionId   string   `json:"sid,omitempty"`
+	Family      string   `json:"fam,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	ClientId    string   `json:"cid,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	Actor       *Actor   `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Actor is the act claim of RFC 8693, identifying who is acting on behalf
+// of the subject.
+type Actor struct {
+	Subject  string `json:"sub"`
+	Username string `json:"username,omitempty"`
+}
+
+// GenerateAccessToken issues an access token for the user's session.
+// clientId and scope are empty for first-party logins. Tokens of OAuth
+// clients carry no roles and only the permissions of the user their scope
+// names, a client granted openid cannot act as the admin it signed in.
+func GenerateAccessToken(user entity.User, sessionId, clientId, scope string) (string, error) {
+	roles, permissions := user.RoleNames(), user.PermissionNames()
+	if clientId != "" {
+		roles = nil
+		permissions = scopedPermissions(permissions, scope)
+	}
+
+	now := time.Now()
+	claims := Claims{
+		Type:        tokenTypeAccess,
+		Id:          user.Id.String(),
+		Username:    user.Username,
+		Name:        user.Name,
+		SessionId:   sessionId,
+		Roles:       roles,
+		Permissions: permissions,
+		ClientId:    clientId,
+		Scope:       scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.Id.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	}
+
+	return accessKeys.Sign(claims)
+}
+
+func scopedPermissions(permissions []string, scope string) []string {
+	scopes := strings.Fields(scope)
+
+	var granted []string
+	for _, permission := range permissions {
+		if hasScope(scopes, permission) {
+			granted = append(granted, permission)
+		}
+	}
+	return granted
+}
+
+// GenerateImpersonationToken issues a short-lived access token for user
+// carrying actor in the act claim. It belongs to the session of the actor,
+// so ending that session ends the impersonation too.
+func GenerateImpersonationToken(user, actor entity.User, sessionId string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Type:        tokenTypeAccess,
+		Id:          user.Id.String(),
+		Username:    user.Username,
+		Name:        user.Name,
+		SessionId:   sessionId,
+		Roles:       user.RoleNames(),
+		Permissions: user.PermissionNames(),
+		Actor: &Actor{
+			Subject:  actor.Id.String(),
+			Username: actor.Username,
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.Id.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ImpersonationTTL)),
+		},
+	}
+
+	return accessKeys.Sign(claims)
+}
+
+// GenerateClientAccessToken issues an access token for an OAuth client
+// acting on its own behalf.
+func GenerateClientAccessToken(clientId, scope string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Type:     tokenTypeAccess,
+		ClientId: clientId,
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   clientId,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	}
+
+	return accessKeys.Sign(claims)
+}
+
+// IDTokenClaims are the claims of an OpenID Connect ID token.
+type IDTokenClaims struct {
+	AuthTime          int64  `json:"auth_time"`
+	Nonce             string `json:"nonce,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GenerateIDToken issues an ID token for the client. Profile and email
+// claims are only included when the scope asks for them.
+func GenerateIDToken(user entity.User, issuer, clientId, nonce, scope string, authTime time.Time) (string, error) {
+	now := time.Now()
+	claims := IDTokenClaims{
+		AuthTime: authTime.Unix(),
+		Nonce:    nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   user.Id.String(),
+			Audience:  jwt.ClaimStrings{clientId},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	}
+
+	scopes := strings.Fields(scope)
+	if hasScope(scopes, "profile") {
+		claims.Name = user.Name
+		claims.PreferredUsername = user.Username
+	}
+	if hasScope(scopes, "email") {
+		verified := user.EmailVerifiedAt != nil
+		claims.Email = user.Email
+		claims.EmailVerified = &verified
+	}
+
+	return accessKeys.Sign(claims)
+}
 
-func GenerateAccessToken(user entity.User) (string, error) {
+// GenerateRefreshToken issues a refresh token belonging to the given token
+// family. All tokens rotated from the same login share one family.
+func GenerateRefreshToken(user entity.User, family, clientId, scope string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Type:     tokenTypeRefresh,
+		Id:       user.Id.String(),
+		Username: user.Username,
+		Name:     user.Name,
+		Family:   family,
+		ClientId: clientId,
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(RefreshTokenTTL)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(refreshSecret)
+}
+
+// GenerateEmailVerificationToken signs a token proving ownership of the
+// user's current email address. Changing the email invalidates it.
+func GenerateEmailVerificationToken(user entity.User) (string, error) {
 	claims := jwt.MapClaims{
-		"id":       user.Id,
-		"username": user.Username,
-		"name":     user.Name,
-		"exp":      time.Now().Add(15 * time.Minute).Unix(),
+		"id":      user.Id,
+		"email":   user.Email,
+		"purpose": purposeEmailVerification,
+		"exp":     time.Now().Add(EmailVerificationTTL).Unix(),
 	}
 
-	fmt.Println(claims)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(verificationSecret)
+}
+
+func ParseEmailVerificationToken(tokenString string) (jwt.MapClaims, error) {
+	return parsePurpose(tokenString, verificationSecret, purposeEmailVerification)
+}
+
+// GenerateMfaChallengeToken signs a short-lived token proving the user
+// passed the password check. It is exchanged for the session tokens
+// together with a second factor.
+func GenerateMfaChallengeToken(user entity.User) (string, error) {
+	claims := jwt.MapClaims{
+		"id":      user.Id,
+		"purpose": purposeMfaChallenge,
+		"jti":     uuid.NewString(),
+		"exp":     time.Now().Add(MfaChallengeTTL).Unix(),
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString(mfaSecret)
 }
 
-func GenerateRefreshToken(user entity.User) (string, error) {
-	fmt.Println(user)
+func ParseMfaChallengeToken(tokenString string) (jwt.MapClaims, error) {
+	return parsePurpose(tokenString, mfaSecret, purposeMfaChallenge)
+}
+
+// GenerateMagicLinkToken signs a short-lived sign-in token for the user.
+// fingerprint identifies the device that asked for the link, the token is
+// only accepted from that device. Like the verification token it is bound
+// to the address it is sent to.
+func GenerateMagicLinkToken(user entity.User, fingerprint string) (string, error) {
 	claims := jwt.MapClaims{
-		"id":       user.Id,
-		"username": user.Username,
-		"name":     user.Name,
-		"exp":      time.Now().Add(7 * 24 * time.Hour).Unix(),
+		"id":      user.Id,
+		"email":   user.Email,
+		"fp":      fingerprint,
+		"purpose": purposeMagicLink,
+		"jti":     uuid.NewString(),
+		"exp":     time.Now().Add(MagicLinkTTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(refreshSecret)
+	return token.SignedString(magicLinkSecret)
+}
+
+func ParseMagicLinkToken(tokenString string) (jwt.MapClaims, error) {
+	return parsePurpose(tokenString, magicLinkSecret, purposeMagicLink)
+}
+
+func parsePurpose(tokenString string, secret []byte, purpose string) (jwt.MapClaims, error) {
+	claims, err := parseWithSecret(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, _ := claims["purpose"].(string); value != purpose {
+		return nil, fmt.Errorf("invalid token purpose")
+	}
+
+	return claims, nil
 }
 
-func ParseToken(tokenString string, isRefresh bool) (jwt.MapClaims, error) {
-	secret := jwtSecret
+// ParseToken verifies an access token, or a refresh token when isRefresh
+// is set, and returns its claims. Tokens of the other type and tokens
+// without a subject, id or expiry are rejected.
+func ParseToken(tokenString string, isRefresh bool) (*Claims, error) {
+	keyfunc, tokenType := accessKeys.Keyfunc, tokenTypeAccess
 	if isRefresh {
-		secret = refreshSecret
+		tokenType = tokenTypeRefresh
+		keyfunc = func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
+			return refreshSecret, nil
+		}
 	}
 
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyfunc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid || claims.Type != tokenType || (claims.Id == "" && claims.ClientId == "") || claims.ID == "" || claims.ExpiresAt == nil || claims.IssuedAt == nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
+}
+
+func parseWithSecret(tokenString string, secret []byte) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
@@ -67,30 +412,14 @@ func ParseToken(tokenString string, isRefresh bool) (jwt.MapClaims, error) {
 		return nil, fmt.Errorf("token has expired")
 	}
 
-	if _, ok := claims["username"]; !ok {
-		claims["username"] = ""
-	}
-	if _, ok := claims["name"]; !ok {
-		claims["name"] = ""
-	}
-
 	return claims, nil
 }
 
-var TokenBlacklist = struct {
-	sync.RWMutex
-	tokens map[string]struct{}
-}{tokens: make(map[string]struct{})}
-
-func BlacklistToken(token string) {
-	TokenBlacklist.Lock()
-	defer TokenBlacklist.Unlock()
-	TokenBlacklist.tokens[token] = struct{}{}
-}
-
-func IsBlacklisted(token string) bool {
-	TokenBlacklist.RLock()
-	defer TokenBlacklist.RUnlock()
-	_, exists := TokenBlacklist.tokens[token]
-	return exists
+func hasScope(scopes []string, scope string) bool {
+	for _, value := range scopes {
+		if value == scope {
+			return true
+		}
+	}
+	return false
 }
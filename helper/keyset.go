@@ -0,0 +1,246 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// SigningKey is one key of a KeySet. Keys loaded from a public key only
+// can verify tokens but not sign them.
+type SigningKey struct {
+	Id        string
+	Method    jwt.SigningMethod
+	Private   crypto.PrivateKey
+	Public    crypto.PublicKey
+	Symmetric []byte
+}
+
+// KeySet signs access tokens with its active key and verifies them with
+// any of its keys, selected by the kid header. Rotating means adding a new
+// key, making it active and removing the old one once the tokens it signed
+// have expired.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACKeySet returns a key set signing with HS256 and a shared secret.
+// It publishes no keys, HMAC tokens can only be verified by services that
+// know the secret.
+func NewHMACKeySet(secret []byte) *KeySet {
+	if secret == nil {
+		// a nil Symmetric marks an asymmetric key
+		secret = []byte{}
+	}
+	key := &SigningKey{Method: jwt.SigningMethodHS256, Symmetric: secret}
+	return &KeySet{active: key, keys: map[string]*SigningKey{"": key}}
+}
+
+// LoadKeySet reads every *.pem file of dir. The file name without the
+// extension is the key id. activeId selects the signing key and must name
+// a private key; when empty the only key of the directory is used.
+func LoadKeySet(dir, activeId string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.pem keys found in %s", dir)
+	}
+
+	set := &KeySet{keys: map[string]*SigningKey{}}
+	for _, path := range paths {
+		key, err := loadKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		set.keys[key.Id] = key
+	}
+
+	if activeId == "" && len(set.keys) == 1 {
+		for id := range set.keys {
+			activeId = id
+		}
+	}
+
+	active, ok := set.keys[activeId]
+	if !ok {
+		return nil, fmt.Errorf("active key %q not found in %s", activeId, dir)
+	}
+	if active.Private == nil {
+		return nil, fmt.Errorf("active key %q has no private key", activeId)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+// Algorithm returns the JWS algorithm of the active key.
+func (s *KeySet) Algorithm() string {
+	return s.active.Method.Alg()
+}
+
+// Sign signs the claims with the active key, setting the kid header.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	if s.active.Symmetric != nil {
+		return token.SignedString(s.active.Symmetric)
+	}
+
+	token.Header["kid"] = s.active.Id
+	return token.SignedString(s.active.Private)
+}
+
+// Keyfunc resolves the verification key of a token. The algorithm must be
+// the one of the key, so a public key can never be used as an HMAC secret.
+func (s *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %q", kid)
+	}
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+
+	if key.Symmetric != nil {
+		return key.Symmetric, nil
+	}
+	return key.Public, nil
+}
+
+// JWKS returns the public keys of the set, sorted by id.
+func (s *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range s.keys {
+		if key.Symmetric != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, key.jwk())
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func (k *SigningKey) jwk() JWK {
+	jwk := JWK{Kid: k.Id, Use: "sig", Alg: k.Method.Alg()}
+
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeSegment(public.N.Bytes())
+		jwk.E = encodeSegment(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = public.Curve.Params().Name
+		jwk.X = encodeSegment(public.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeSegment(public.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encodeSegment(public)
+	}
+
+	return jwk
+}
+
+func loadKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &SigningKey{Id: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key.Private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key.Private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key.Private, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key.Public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if signer, ok := key.Private.(crypto.Signer); ok {
+		key.Public = signer.Public()
+	}
+
+	key.Method, err = signingMethod(key.Public)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func signingMethod(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch public.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, fmt.Errorf("unsupported curve %s", public.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", public)
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
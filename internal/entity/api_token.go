@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (ApiToken) TableName() string {
+	return "api_tokens"
+}
+
+// ApiToken is a long-lived personal access token for scripts and CI jobs.
+// Only the SHA-256 hash of the token is stored, Prefix keeps its first
+// characters so users can tell their tokens apart. Scopes is a space
+// separated list of permission names the token may use.
+type ApiToken struct {
+	Id         uuid.UUID  `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	UserId     uuid.UUID  `gorm:"type:uuid; not null; index;" json:"user_id"`
+	Name       string     `gorm:"type:character varying; not null;" json:"name"`
+	Prefix     string     `gorm:"type:character varying; not null;" json:"prefix"`
+	TokenHash  string     `gorm:"type:character varying; not null; uniqueIndex;" json:"-"`
+	Scopes     string     `gorm:"type:text; not null;" json:"scopes"`
+	ExpiresAt  time.Time  `gorm:"type:timestamptz; not null;" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"type:timestamptz;" json:"last_used_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime;" json:"created_at"`
+}
+
+// IsExpired reports whether the token can no longer be used.
+func (t ApiToken) IsExpired() bool {
+	return !time.Now().Before(t.ExpiresAt)
+}
+
+// ScopeList returns the scopes as a slice.
+func (t ApiToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodMagicLink = "magic_link"
+	LoginMethodMfa       = "mfa"
+)
+
+func (LoginEvent) TableName() string {
+	return "login_events"
+}
+
+// LoginEvent records a sign-in attempt on a known account. Network is the
+// /24 (IPv4) or /48 (IPv6) range of the IP, used to tell a new place from
+// a changed address; Country and City are only set when GeoIP is
+// configured. Device is the hash of the device cookie, empty for clients
+// without one.
+type LoginEvent struct {
+	Id            uuid.UUID `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	UserId        uuid.UUID `gorm:"type:uuid; not null; index:idx_login_events_user_created;" json:"-"`
+	Method        string    `gorm:"type:character varying; not null;" json:"method"`
+	Success       bool      `gorm:"not null;" json:"success"`
+	FailureReason string    `gorm:"type:character varying;" json:"failure_reason,omitempty"`
+	IP            string    `gorm:"type:character varying;" json:"ip"`
+	Network       string    `gorm:"type:character varying;" json:"network"`
+	Country       string    `gorm:"type:character varying;" json:"country"`
+	City          string    `gorm:"type:character varying;" json:"city"`
+	UserAgent     string    `gorm:"type:character varying;" json:"user_agent"`
+	Device        string    `gorm:"type:character varying;" json:"-"`
+	CreatedAt     time.Time `gorm:"autoCreateTime; index:idx_login_events_user_created;" json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
+
+// LoginThrottle counts consecutive failed logins for one key, either an
+// account ("account:<email>"), a client address ("ip:<address>") or an MFA
+// challenge ("challenge:<jti>").
+type LoginThrottle struct {
+	Key           string     `gorm:"type:character varying; primaryKey;" json:"key"`
+	Failures      int        `gorm:"type:int; not null; default:0;" json:"failures"`
+	LastFailureAt time.Time  `gorm:"type:timestamptz; not null;" json:"last_failure_at"`
+	LockedUntil   *time.Time `gorm:"type:timestamptz;" json:"locked_until"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime;" json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (MfaRecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MfaRecoveryCode is a single-use code that replaces a TOTP code when the
+// user lost their authenticator. Only the SHA-256 hash is stored.
+type MfaRecoveryCode struct {
+	Id        uuid.UUID  `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	UserId    uuid.UUID  `gorm:"type:uuid; not null; index;" json:"user_id"`
+	CodeHash  string     `gorm:"type:character varying; not null; unique;" json:"-"`
+	UsedAt    *time.Time `gorm:"type:timestamptz;" json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;" json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// OAuthAuthorizationCode is a single-use code handed to the client by the
+// authorization endpoint. Only the SHA-256 hash of the code is stored.
+type OAuthAuthorizationCode struct {
+	Id            uuid.UUID  `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	CodeHash      string     `gorm:"type:character varying; not null; unique;" json:"-"`
+	ClientId      string     `gorm:"type:character varying; not null;" json:"client_id"`
+	UserId        uuid.UUID  `gorm:"type:uuid; not null; index;" json:"user_id"`
+	RedirectURI   string     `gorm:"type:text; not null;" json:"redirect_uri"`
+	Scope         string     `gorm:"type:text; not null;" json:"scope"`
+	Nonce         string     `gorm:"type:character varying;" json:"nonce"`
+	CodeChallenge string     `gorm:"type:character varying; not null;" json:"-"`
+	AuthTime      time.Time  `gorm:"type:timestamptz; not null;" json:"auth_time"`
+	ExpiresAt     time.Time  `gorm:"type:timestamptz; not null;" json:"expires_at"`
+	UsedAt        *time.Time `gorm:"type:timestamptz;" json:"used_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime;" json:"created_at"`
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// OAuthClient is an application allowed to request tokens through the
+// OAuth endpoints. Public clients, such as SPAs and mobile apps, have no
+// secret and must use PKCE. RedirectURIs, Scopes and GrantTypes are space
+// separated lists.
+type OAuthClient struct {
+	Id           uuid.UUID `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	ClientId     string    `gorm:"type:character varying; not null; unique;" json:"client_id"`
+	SecretHash   string    `gorm:"type:character varying;" json:"-"`
+	Name         string    `gorm:"type:character varying; not null;" json:"name"`
+	RedirectURIs string    `gorm:"type:text; not null;" json:"redirect_uris"`
+	Scopes       string    `gorm:"type:text; not null;" json:"scopes"`
+	GrantTypes   string    `gorm:"type:text; not null;" json:"grant_types"`
+	Confidential bool      `gorm:"not null; default:false;" json:"confidential"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime;" json:"updated_at"`
+}
+
+// AllowsRedirectURI reports whether uri is registered. Redirect URIs are
+// compared exactly.
+func (c OAuthClient) AllowsRedirectURI(uri string) bool {
+	return containsField(c.RedirectURIs, uri)
+}
+
+// AllowsGrant reports whether the client may use the grant type.
+func (c OAuthClient) AllowsGrant(grant string) bool {
+	return containsField(c.GrantTypes, grant)
+}
+
+// AllowsScope reports whether the client may request the scope.
+func (c OAuthClient) AllowsScope(scope string) bool {
+	return containsField(c.Scopes, scope)
+}
+
+func containsField(list, value string) bool {
+	for _, field := range strings.Fields(list) {
+		if field == value {
+			return true
+		}
+	}
+	return false
+}
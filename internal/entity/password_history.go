@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
+
+// PasswordHistory keeps the hashes of a user's previous passwords so they
+// cannot be reused. Only the most recent ones required by the policy are
+// kept.
+type PasswordHistory struct {
+	Id        uuid.UUID `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	UserId    uuid.UUID `gorm:"type:uuid; not null; index;" json:"user_id"`
+	Password  string    `gorm:"type:character varying; not null;" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime;" json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (PasswordReset) TableName() string {
+	return "password_resets"
+}
+
+// PasswordReset is a single-use password reset token. Only the SHA-256 hash
+// of the token is stored, the plain value exists in the emailed link only.
+type PasswordReset struct {
+	Id        uuid.UUID  `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	UserId    uuid.UUID  `gorm:"type:uuid; not null; index;" json:"user_id"`
+	TokenHash string     `gorm:"type:character varying; not null; unique;" json:"-"`
+	ExpiresAt time.Time  `gorm:"type:timestamptz; not null;" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"type:timestamptz;" json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;" json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PermissionUsersCreate      = "users.create"
+	PermissionUsersRead        = "users.read"
+	PermissionUsersUpdate      = "users.update"
+	PermissionUsersDelete      = "users.delete"
+	PermissionUsersImpersonate = "users.impersonate"
+	PermissionRolesRead        = "roles.read"
+	PermissionRolesManage      = "roles.manage"
+
+	PermissionClientsManage = "clients.manage"
+	PermissionMetricsRead   = "metrics.read"
+)
+
+// DefaultPermissions are seeded on startup and granted to the admin role.
+var DefaultPermissions = []Permission{
+	{Name: PermissionUsersCreate, Description: "Create users"},
+	{Name: PermissionUsersRead, Description: "View users and their sessions"},
+	{Name: PermissionUsersUpdate, Description: "Update users and sign them out"},
+	{Name: PermissionUsersDelete, Description: "Delete users"},
+	{Name: PermissionUsersImpersonate, Description: "Sign in as another user for support"},
+	{Name: PermissionRolesRead, Description: "View roles and permissions"},
+	{Name: PermissionRolesManage, Description: "Manage roles and assign them to users"},
+	{Name: PermissionClientsManage, Description: "Register and remove OAuth clients"},
+	{Name: PermissionMetricsRead, Description: "Read the runtime metrics at /debug/vars"},
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+type Permission struct {
+	Id          uuid.UUID `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	Name        string    `gorm:"type:character varying; not null; unique;" json:"name"`
+	Description string    `gorm:"type:character varying;" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;" json:"created_at"`
+}
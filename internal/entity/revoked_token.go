@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// RevokedToken is a token store entry, see the tokenstore package.
+type RevokedToken struct {
+	Key       string    `gorm:"type:character varying; primaryKey;" json:"key"`
+	Value     int64     `gorm:"type:bigint; not null;" json:"value"`
+	ExpiresAt time.Time `gorm:"type:timestamptz; not null; index;" json:"expires_at"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoleAdmin is seeded on startup with every default permission.
+const RoleAdmin = "admin"
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+type Role struct {
+	Id          uuid.UUID    `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	Name        string       `gorm:"type:character varying; not null; unique;" json:"name"`
+	Description string       `gorm:"type:character varying;" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions;" json:"permissions"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime;" json:"created_at"`
+	UpdatedAt   time.Time    `gorm:"autoUpdateTime;" json:"updated_at"`
+}
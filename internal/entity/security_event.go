@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SecurityEventAccountLocked      = "account_locked"
+	SecurityEventAccountUnlocked    = "account_unlocked"
+	SecurityEventRefreshTokenReuse  = "refresh_token_reuse"
+	SecurityEventImpersonationStart = "impersonation_started"
+	SecurityEventImpersonationEnd   = "impersonation_ended"
+)
+
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
+
+// SecurityEvent is an audit record of a security relevant incident.
+type SecurityEvent struct {
+	Id        uuid.UUID  `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	Event     string     `gorm:"type:character varying; not null; index;" json:"event"`
+	UserId    *uuid.UUID `gorm:"type:uuid; index;" json:"user_id"`
+	IP        string     `gorm:"type:character varying;" json:"ip"`
+	UserAgent string     `gorm:"type:character varying;" json:"user_agent"`
+	Details   string     `gorm:"type:text;" json:"details"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;" json:"created_at"`
+}
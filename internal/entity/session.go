@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// Session is a signed-in device. Its Id is also the family of the refresh
+// tokens rotated from that sign-in, so revoking the session revokes them.
+type Session struct {
+	Id         uuid.UUID  `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	UserId     uuid.UUID  `gorm:"type:uuid; not null; index;" json:"user_id"`
+	IP         string     `gorm:"type:character varying; not null;" json:"ip"`
+	UserAgent  string     `gorm:"type:character varying; not null;" json:"user_agent"`
+	Country    string     `gorm:"type:character varying;" json:"country"`
+	City       string     `gorm:"type:character varying;" json:"city"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime;" json:"created_at"`
+	LastSeenAt time.Time  `gorm:"type:timestamptz; not null;" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"type:timestamptz; not null;" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"type:timestamptz;" json:"revoked_at"`
+}
+
+// IsActive reports whether the session can still be used to refresh tokens.
+func (s Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
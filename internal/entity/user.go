@@ -11,21 +11,50 @@ func (User) TableName() string {
 }
 
 type User struct {
-	Id              uuid.UUID `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
-	Username        string    `gorm:"type:character varying; not null; unique;" json:"username"`
-	Name            string    `gorm:"type:character varying; not null;" json:"name"`
-	Email           string    `gorm:"type:character varying; not null; unique;" json:"email"`
-	Status          int       `gorm:"type:int; not null; default:1;" json:"status"`
-	EmailVerifiedAt time.Time `gorm:"autoCreateTime;" json:"email_verified_at"`
-	Password        string    `gorm:"type:character varying; not null;" json:"password"`
-	CreatedAt       time.Time `gorm:"autoCreateTime;" json:"created_at"`
-	UpdatedAt       time.Time `gorm:"autoUpdateTime;" json:"updated_at"`
+	Id              uuid.UUID  `gorm:"type:uuid; primaryKey; default:gen_random_uuid();" json:"id"`
+	Username        string     `gorm:"type:character varying; not null; unique;" json:"username"`
+	Name            string     `gorm:"type:character varying; not null;" json:"name"`
+	Email           string     `gorm:"type:character varying; not null; unique;" json:"email"`
+	Status          int        `gorm:"type:int; not null; default:1;" json:"status"`
+	EmailVerifiedAt *time.Time `gorm:"type:timestamptz;" json:"email_verified_at"`
+	Password        string     `gorm:"type:character varying; not null;" json:"password"`
+	MfaEnabled      bool       `gorm:"not null; default:false;" json:"mfa_enabled"`
+	MfaSecret       string     `gorm:"type:character varying;" json:"-"`
+	MfaLastStep     int64      `gorm:"not null; default:0;" json:"-"`
+	Roles           []Role     `gorm:"many2many:user_roles;" json:"roles,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime;" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime;" json:"updated_at"`
 }
 
 func (User) SearchableFields() []string {
 	return []string{"username", "email"}
 }
 
+// RoleNames returns the names of the user's roles. Roles must be preloaded.
+func (u User) RoleNames() []string {
+	names := []string{}
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// PermissionNames returns the distinct permissions granted by the user's
+// roles. Roles and their permissions must be preloaded.
+func (u User) PermissionNames() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, role := range u.Roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				names = append(names, permission.Name)
+			}
+		}
+	}
+	return names
+}
+
 type UserFilters struct {
 	Status *string
 }
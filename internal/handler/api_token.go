@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ApiTokenHandler struct {
+	IApiTokenService service.IApiTokenService
+}
+
+func NewApiTokenHandler(serv service.IApiTokenService) *ApiTokenHandler {
+	return &ApiTokenHandler{IApiTokenService: serv}
+}
+
+// ApiTokenVerifier lets the authenticator accept personal access tokens.
+func ApiTokenVerifier(serv service.IApiTokenService) middleware.ApiTokenVerifier {
+	return func(token string) (*middleware.Principal, error) {
+		identity, err := serv.Authenticate(token)
+		if err != nil {
+			return nil, err
+		}
+
+		return &middleware.Principal{
+			Id:          identity.User.Id.String(),
+			Username:    identity.User.Username,
+			Name:        identity.User.Name,
+			Roles:       identity.User.RoleNames(),
+			Permissions: identity.Permissions,
+			ApiTokenId:  identity.TokenId.String(),
+		}, nil
+	}
+}
+
+// Create API Token godoc
+// @Summary Create a personal access token
+// @Description Create a long-lived token for scripts and CI jobs, sent as "Authorization: Bearer gfs_...".
+// @Description Scopes must be permissions the user holds. The token is only shown in this response.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.ApiTokenCreateRequest true "API Token Create Request"
+// @Success 201 {object} response.JSON{data=response.ApiTokenResponse} "A new record has been stored."
+// @Failure 400 {object} response.JSON "Bad request"
+// @Failure 403 {object} response.JSON "API tokens and OAuth client tokens cannot manage API tokens"
+// @Router /api/v1/auth/tokens [post]
+func (handler *ApiTokenHandler) Create(ctx *fiber.Ctx) error {
+	userId, err := sessionUser(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusForbidden, err.Error())
+	}
+
+	req := request.ApiTokenCreateRequest{}
+	if err := ctx.BodyParser(&req); err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+	req.UserId = userId
+
+	apiToken, err := handler.IApiTokenService.Create(req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.JSON{
+				Status:  400,
+				Message: "invalid token data",
+				Errors:  err.Error(),
+			})
+		case errors.Is(err, service.ErrApiTokenScope):
+			return errorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		default:
+			logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to create api token")
+			return errorResponse(ctx, fiber.StatusInternalServerError, "failed to create api token")
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.JSON{
+		Status:  201,
+		Message: "A new record has been stored.",
+		Data:    apiToken,
+	})
+}
+
+// Find My API Tokens godoc
+// @Summary List my personal access tokens
+// @Description Retrieve the personal access tokens of the authenticated user, without their secret part
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.JSON{data=[]response.ApiTokenResponse} "Successfully retrieved all records."
+// @Failure 403 {object} response.JSON "API tokens and OAuth client tokens cannot manage API tokens"
+// @Router /api/v1/auth/tokens [get]
+func (handler *ApiTokenHandler) FindAll(ctx *fiber.Ctx) error {
+	userId, err := sessionUser(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusForbidden, err.Error())
+	}
+
+	apiTokens, err := handler.IApiTokenService.FindAllByUserId(userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.JSON{
+			Status:  500,
+			Message: "Failed to retrieve records",
+			Errors:  err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved all records.",
+		Data:    apiTokens,
+	})
+}
+
+// Find My API Token godoc
+// @Summary Get a personal access token
+// @Description Retrieve one of the authenticated user's personal access tokens
+// @Tags Auth
+// @Produce json
+// @Param id path string true "Token ID"
+// @Success 200 {object} response.JSON{data=response.ApiTokenResponse} "Successfully retrieved the record."
+// @Failure 404 {object} response.JSON "Not found"
+// @Router /api/v1/auth/tokens/{id} [get]
+func (handler *ApiTokenHandler) FindById(ctx *fiber.Ctx) error {
+	userId, err := sessionUser(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusForbidden, err.Error())
+	}
+
+	tokenId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	apiToken, err := handler.IApiTokenService.FindById(userId, tokenId)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusNotFound, err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved the record.",
+		Data:    apiToken,
+	})
+}
+
+// Delete My API Token godoc
+// @Summary Revoke a personal access token
+// @Description Delete one of the authenticated user's personal access tokens, it stops working immediately
+// @Tags Auth
+// @Produce json
+// @Param id path string true "Token ID"
+// @Success 200 {object} response.JSON "Selected record has been deleted."
+// @Failure 404 {object} response.JSON "Not found"
+// @Router /api/v1/auth/tokens/{id} [delete]
+func (handler *ApiTokenHandler) Delete(ctx *fiber.Ctx) error {
+	userId, err := sessionUser(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusForbidden, err.Error())
+	}
+
+	tokenId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	if err := handler.IApiTokenService.Delete(userId, tokenId); err != nil {
+		if errors.Is(err, service.ErrApiTokenNotFound) {
+			return errorResponse(ctx, fiber.StatusNotFound, err.Error())
+		}
+		logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to delete api token")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to delete api token")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Selected record has been deleted.",
+	})
+}
+
+// sessionUser returns the user of a signed-in session. Personal access
+// tokens and tokens of OAuth clients are rejected, otherwise a leaked
+// token could mint new ones with every permission of the user.
+func sessionUser(ctx *fiber.Ctx) (uuid.UUID, error) {
+	principal := middleware.GetPrincipal(ctx)
+	if principal == nil {
+		return uuid.Nil, middleware.ErrNoToken
+	}
+	if principal.IsApiToken() {
+		return uuid.Nil, errors.New("api tokens cannot manage api tokens")
+	}
+	if principal.ClientId != "" {
+		return uuid.Nil, errors.New("oauth client tokens cannot manage api tokens")
+	}
+	return principal.UserId()
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
-	"strings"
+	"errors"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/fatihrizqon/go-fiber-service/helper"
@@ -10,32 +12,100 @@ import (
 	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
 	"github.com/fatihrizqon/go-fiber-service/internal/service"
 	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/fatihrizqon/go-fiber-service/password"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
-	IAuthService service.IAuthService
+	IAuthService  service.IAuthService
+	ITokenService service.ITokenService
+	IMfaService   service.IMfaService
 }
 
-func NewAuthHandler(serv service.IAuthService) *AuthHandler {
-	return &AuthHandler{IAuthService: serv}
+func NewAuthHandler(serv service.IAuthService, tokenServ service.ITokenService, mfaServ service.IMfaService) *AuthHandler {
+	return &AuthHandler{IAuthService: serv, ITokenService: tokenServ, IMfaService: mfaServ}
+}
+
+// Register godoc
+// @Summary User registration
+// @Description Create a new account with a unique username and email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.RegisterRequest true "Register request"
+// @Success 201 {object} response.JSON "Account has been registered."
+// @Failure 400 {object} response.JSON "Invalid request format"
+// @Failure 409 {object} response.JSON "Username or email already taken, or already authenticated"
+// @Failure 422 {object} response.JSON{errors=[]password.Violation} "Password rejected by the password policy"
+// @Failure 503 {object} response.JSON "Password hashing is busy, see Retry-After"
+// @Router /api/v1/auth/register [post]
+func (handler *AuthHandler) Register(ctx *fiber.Ctx) error {
+	log := logger.GetLogger()
+	ip := helper.ClientIP(ctx)
+
+	var req request.RegisterRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		log.WithField("ip", ip).Error("failed to parse register request")
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	result, err := handler.IAuthService.Register(ctx.UserContext(), req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		var policyErr *password.PolicyError
+		var saturatedErr *password.SaturatedError
+		switch {
+		case errors.As(err, &validationErrors):
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.JSON{
+				Status:  fiber.StatusBadRequest,
+				Message: "invalid registration data",
+				Errors:  err.Error(),
+			})
+		case errors.Is(err, service.ErrUsernameTaken), errors.Is(err, service.ErrEmailTaken):
+			return errorResponse(ctx, fiber.StatusConflict, err.Error())
+		case errors.As(err, &policyErr):
+			return policyResponse(ctx, policyErr)
+		case errors.As(err, &saturatedErr):
+			return busyResponse(ctx, saturatedErr)
+		default:
+			log.WithField("ip", ip).WithError(err).Error("registration failed: " + req.Email)
+			return errorResponse(ctx, fiber.StatusInternalServerError, "registration failed")
+		}
+	}
+
+	log.WithField("ip", ip).Info("user registered: " + result.Email)
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.JSON{
+		Status:  fiber.StatusCreated,
+		Message: "Account has been registered.",
+		Data:    result,
+	})
 }
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate user and return a JWT token in a cookie
+// @Description Authenticate user and return a JWT token in a cookie.
+// @Description Users with two-factor authentication get a challenge token instead, to be completed at /api/v1/auth/mfa/verify.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param request body request.LoginRequest true "Login request"
 // @Success 200 {object} response.AuthJSON
+// @Success 202 {object} response.JSON{data=response.MfaChallengeResponse} "Two-factor authentication required"
 // @Failure 400 {object} response.JSON "Invalid request format"
 // @Failure 401 {object} response.JSON "Authentication failed"
+// @Failure 403 {object} response.JSON "Email address has not been verified"
+// @Failure 409 {object} response.JSON "Already authenticated"
+// @Failure 423 {object} response.JSON "Account is temporarily locked, code account_locked"
+// @Failure 429 {object} response.JSON "Too many failed attempts, code too_many_attempts"
+// @Failure 503 {object} response.JSON "Password hashing is busy, see Retry-After"
 // @Router /api/v1/auth/login [post]
 func (handler *AuthHandler) Login(ctx *fiber.Ctx) error {
 	log := logger.GetLogger()
-	ip := ctx.IP()
+	ip := helper.ClientIP(ctx)
 
 	var req request.LoginRequest
 	if err := ctx.BodyParser(&req); err != nil {
@@ -45,37 +115,205 @@ func (handler *AuthHandler) Login(ctx *fiber.Ctx) error {
 
 	log.WithField("ip", ip).Info("user login attempt: " + req.Email)
 
-	result, err := handler.IAuthService.Login(req)
+	result, err := handler.IAuthService.Login(ctx.UserContext(), req, loginClientInfo(ctx))
 	if err != nil {
 		log.WithField("ip", ip).Error("authentication failed: " + req.Email)
-		return errorResponse(ctx, fiber.StatusUnauthorized, "authentication failed: "+err.Error())
+
+		var throttleErr *service.ThrottleError
+		var saturatedErr *password.SaturatedError
+		switch {
+		case errors.As(err, &throttleErr):
+			return throttledResponse(ctx, throttleErr)
+		case errors.As(err, &saturatedErr):
+			return busyResponse(ctx, saturatedErr)
+		case errors.Is(err, service.ErrEmailNotVerified):
+			return errorResponse(ctx, fiber.StatusForbidden, err.Error())
+		default:
+			return errorResponse(ctx, fiber.StatusUnauthorized, "authentication failed: "+err.Error())
+		}
 	}
 
-	accessToken, _ := helper.GenerateAccessToken(result.User)
-	refreshToken, _ := helper.GenerateRefreshToken(result.User)
+	if result.User.MfaEnabled {
+		challenge, err := handler.IMfaService.Challenge(result.User)
+		if err != nil {
+			log.WithField("ip", ip).WithError(err).Error("failed to issue mfa challenge: " + req.Email)
+			return errorResponse(ctx, fiber.StatusInternalServerError, "failed to issue tokens")
+		}
+
+		log.WithField("ip", ip).Info("mfa challenge issued: " + req.Email)
+
+		return ctx.Status(fiber.StatusAccepted).JSON(response.JSON{
+			Status:  fiber.StatusAccepted,
+			Message: "two-factor authentication required",
+			Data:    challenge,
+		})
+	}
+
+	tokens, err := handler.ITokenService.Issue(result.User, clientInfo(ctx))
+	if err != nil {
+		log.WithField("ip", ip).WithError(err).Error("failed to issue tokens: " + req.Email)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to issue tokens")
+	}
 
-	setAuthCookies(ctx, accessToken, refreshToken)
+	setAuthCookies(ctx, tokens.AccessToken, tokens.RefreshToken)
 
 	log.WithField("ip", ip).Info("user logged in: " + req.Email)
 
 	return ctx.Status(fiber.StatusOK).JSON(response.AuthJSON{
-		Message: "you are authenticated",
+		Message:     "you are authenticated",
+		Status:      fiber.StatusOK,
+		User:        userInfo(result.User),
+		AccessToken: tokens.AccessToken,
+	})
+}
+
+// Verify Email godoc
+// @Summary Verify email address
+// @Description Mark the user's email address as verified using the token sent by email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.VerifyEmailRequest true "Verify email request"
+// @Success 200 {object} response.JSON "Email address has been verified"
+// @Failure 400 {object} response.JSON "Invalid or expired verification token"
+// @Router /api/v1/auth/verify-email [post]
+func (handler *AuthHandler) VerifyEmail(ctx *fiber.Ctx) error {
+	var req request.VerifyEmailRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	if err := handler.IAuthService.VerifyEmail(req); err != nil {
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			return errorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		}
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "email address has been verified",
+	})
+}
+
+// Resend Verification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link if the address belongs to an unverified account
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.ResendVerificationRequest true "Resend verification request"
+// @Success 200 {object} response.JSON "Verification email sent if the account exists"
+// @Failure 400 {object} response.JSON "Invalid request format"
+// @Router /api/v1/auth/verify-email/resend [post]
+func (handler *AuthHandler) ResendVerification(ctx *fiber.Ctx) error {
+	var req request.ResendVerificationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	if err := handler.IAuthService.ResendVerification(req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
 		Status:  fiber.StatusOK,
-		User: response.UserInfo{
-			Id:              result.User.Id,
-			Username:        result.User.Username,
-			Name:            result.User.Name,
-			Email:           result.User.Email,
-			Status:          result.User.Status,
-			EmailVerifiedAt: result.User.EmailVerifiedAt.Format(time.RFC3339),
-		},
-		AccessToken: accessToken,
+		Message: "if the account exists and is not verified yet, a verification email has been sent",
+	})
+}
+
+// Forgot Password godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.ForgotPasswordRequest true "Forgot password request"
+// @Success 200 {object} response.JSON "Reset link sent if the account exists"
+// @Failure 400 {object} response.JSON "Invalid request format"
+// @Failure 409 {object} response.JSON "Already authenticated"
+// @Router /api/v1/auth/forgot-password [post]
+func (handler *AuthHandler) ForgotPassword(ctx *fiber.Ctx) error {
+	log := logger.GetLogger()
+	ip := helper.ClientIP(ctx)
+
+	var req request.ForgotPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	if err := handler.IAuthService.ForgotPassword(req); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+		}
+		log.WithField("ip", ip).WithError(err).Error("failed to issue password reset")
+	}
+
+	log.WithField("ip", ip).Info("password reset requested")
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "if the account exists, a password reset link has been sent",
+	})
+}
+
+// Reset Password godoc
+// @Summary Reset password
+// @Description Set a new password using a reset token. All existing sessions of the user are signed out.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.ResetPasswordRequest true "Reset password request"
+// @Success 200 {object} response.JSON "Password has been reset"
+// @Failure 400 {object} response.JSON "Invalid or expired password reset token"
+// @Failure 422 {object} response.JSON{errors=[]password.Violation} "Password rejected by the password policy"
+// @Failure 503 {object} response.JSON "Password hashing is busy, see Retry-After"
+// @Router /api/v1/auth/reset-password [post]
+func (handler *AuthHandler) ResetPassword(ctx *fiber.Ctx) error {
+	log := logger.GetLogger()
+	ip := helper.ClientIP(ctx)
+
+	var req request.ResetPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	if err := handler.IAuthService.ResetPassword(ctx.UserContext(), req); err != nil {
+		var validationErrors validator.ValidationErrors
+		var policyErr *password.PolicyError
+		var saturatedErr *password.SaturatedError
+		switch {
+		case errors.As(err, &validationErrors):
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.JSON{
+				Status:  fiber.StatusBadRequest,
+				Message: "invalid request format",
+				Errors:  err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidResetToken):
+			return errorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		case errors.As(err, &policyErr):
+			return policyResponse(ctx, policyErr)
+		case errors.As(err, &saturatedErr):
+			return busyResponse(ctx, saturatedErr)
+		default:
+			log.WithField("ip", ip).WithError(err).Error("failed to reset password")
+			return errorResponse(ctx, fiber.StatusInternalServerError, "failed to reset password")
+		}
+	}
+
+	log.WithField("ip", ip).Info("password has been reset")
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "password has been reset",
 	})
 }
 
 // Refresh Token godoc
 // @Summary Refresh access token
-// @Description Refresh the access token using the refresh token from HttpOnly cookie
+// @Description Refresh the access token using the refresh token from HttpOnly cookie.
+// @Description The refresh token is rotated on every call, reusing an old one revokes the whole session.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -89,26 +327,17 @@ func (handler *AuthHandler) Refresh(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, fiber.StatusUnauthorized, "refresh token required")
 	}
 
-	claims, err := helper.ParseToken(refreshToken, true)
+	tokens, err := handler.ITokenService.Rotate(refreshToken, clientInfo(ctx))
 	if err != nil {
-		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid refresh token")
-	}
-
-	userID, err := parseUserIDFromClaims(claims)
-	if err != nil {
-		return errorResponse(ctx, fiber.StatusInternalServerError, "invalid user ID")
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			clearAuthCookies(ctx)
+			return errorResponse(ctx, fiber.StatusUnauthorized, "invalid refresh token")
+		}
+		logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to refresh tokens")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to refresh tokens")
 	}
 
-	username, _ := claims["username"].(string)
-	name, _ := claims["name"].(string)
-
-	accessToken, _ := helper.GenerateAccessToken(entity.User{
-		Id:       userID,
-		Username: username,
-		Name:     name,
-	})
-
-	setAuthCookies(ctx, accessToken, refreshToken)
+	setAuthCookies(ctx, tokens.AccessToken, tokens.RefreshToken)
 
 	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
 		Status:  fiber.StatusOK,
@@ -119,68 +348,63 @@ func (handler *AuthHandler) Refresh(ctx *fiber.Ctx) error {
 // Get User Info godoc
 // @Summary Get authenticated user info
 // @Description Retrieve the current authenticated user's information using the access token stored in HttpOnly cookie or Authorization header.
+// @Description While an admin impersonates the user, impersonator names the admin.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Success 200 {object} response.AuthJSON "User info retrieved"
 // @Failure 401 {object} response.JSON "Invalid or missing access token"
+// @Failure 404 {object} response.JSON "User not found"
 // @Router /api/v1/auth/me [get]
 func (handler *AuthHandler) Me(ctx *fiber.Ctx) error {
-	accessToken := ctx.Cookies("access_token")
-
-	if accessToken == "" {
-		authHeader := ctx.Get("Authorization")
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			accessToken = strings.TrimPrefix(authHeader, "Bearer ")
-		}
-	}
-
-	if accessToken == "" {
+	principal := middleware.GetPrincipal(ctx)
+	if principal == nil {
 		return errorResponse(ctx, fiber.StatusUnauthorized, "access token required")
 	}
 
-	claims, err := helper.ParseToken(accessToken, false)
+	userID, err := principal.UserId()
 	if err != nil {
-		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid user ID")
 	}
 
-	userID, err := parseUserIDFromClaims(claims)
+	user, err := handler.IAuthService.FindById(userID)
 	if err != nil {
-		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid user ID")
+		return errorResponse(ctx, fiber.StatusNotFound, "user not found")
 	}
 
-	username, _ := claims["username"].(string)
-	name, _ := claims["name"].(string)
-	email, _ := claims["email"].(string)
+	// roles and permissions are those of the token, an OAuth client only
+	// holds what its scope grants
+	info := userInfo(user)
+	info.Roles = principal.Roles
+	info.Permissions = principal.Permissions
 
-	statusFloat, _ := claims["status"].(float64)
-	status := int(statusFloat)
+	var impersonator *response.Impersonator
+	if principal.IsImpersonated() {
+		actorId, _ := uuid.Parse(principal.ActorId)
+		impersonator = &response.Impersonator{Id: actorId, Username: principal.ActorUsername}
+	}
 
 	return ctx.Status(fiber.StatusOK).JSON(response.AuthJSON{
-		Message: "user info retrieved",
-		Status:  fiber.StatusOK,
-		User: response.UserInfo{
-			Id:       userID,
-			Username: username,
-			Name:     name,
-			Email:    email,
-			Status:   status,
-		},
+		Message:      "user info retrieved",
+		Status:       fiber.StatusOK,
+		User:         info,
+		Impersonator: impersonator,
 	})
 }
 
 // Logout godoc
 // @Summary Logout user
 // @Description Logout user dengan menghapus access_token dan refresh_token dari cookie,
-// @Description serta memasukkan refresh token ke blacklist.
+// @Description serta mencabut kedua token tersebut di token store.
 // @Tags Auth
 // @Success 200 {object} response.JSON "Successfully logged out"
 // @Failure 401 {object} response.JSON "Unauthorized"
 // @Router /api/v1/auth/logout [post]
 func (handler *AuthHandler) Logout(ctx *fiber.Ctx) error {
-	refreshToken := ctx.Cookies("refresh_token")
-
-	helper.BlacklistToken(refreshToken)
+	if err := handler.ITokenService.Revoke(ctx.Cookies("access_token"), ctx.Cookies("refresh_token")); err != nil {
+		logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to revoke tokens")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to log out")
+	}
 
 	clearAuthCookies(ctx)
 
@@ -190,21 +414,35 @@ func (handler *AuthHandler) Logout(ctx *fiber.Ctx) error {
 	})
 }
 
+// deviceCookie holds a random id identifying the browser. Sign-ins from a
+// new device are reported to the user, and magic links only work in the
+// browser that asked for them.
+const (
+	deviceCookie    = "device_id"
+	deviceCookieTTL = 365 * 24 * time.Hour
+)
+
 // setAuthCookies sets access & refresh tokens as HttpOnly cookies
 func setAuthCookies(ctx *fiber.Ctx, accessToken, refreshToken string) {
+	setAccessCookie(ctx, accessToken, time.Now().Add(helper.AccessTokenTTL))
+
 	ctx.Cookie(&fiber.Cookie{
-		Name:     "access_token",
-		Value:    accessToken,
-		Expires:  time.Now().Add(15 * time.Minute),
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		Expires:  time.Now().Add(helper.RefreshTokenTTL),
 		HTTPOnly: true,
 		Secure:   true,
 		SameSite: "Lax",
 	})
+}
 
+// setAccessCookie replaces the access token cookie alone, leaving the
+// refresh token of the session in place
+func setAccessCookie(ctx *fiber.Ctx, accessToken string, expires time.Time) {
 	ctx.Cookie(&fiber.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshToken,
-		Expires:  time.Now().Add(7 * 24 * time.Hour),
+		Name:     "access_token",
+		Value:    accessToken,
+		Expires:  expires,
 		HTTPOnly: true,
 		Secure:   true,
 		SameSite: "Lax",
@@ -223,12 +461,115 @@ func clearAuthCookies(ctx *fiber.Ctx) {
 	})
 }
 
-func parseUserIDFromClaims(claims map[string]interface{}) (uuid.UUID, error) {
-	idStr, ok := claims["id"].(string)
-	if !ok {
-		return uuid.UUID{}, fiber.ErrInternalServerError
+func clientInfo(ctx *fiber.Ctx) service.ClientInfo {
+	device, _ := deviceFingerprint(ctx, false)
+	location := middleware.GetLocation(ctx)
+
+	return service.ClientInfo{
+		IP:        helper.ClientIP(ctx),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		Device:    device,
+		Country:   location.Country,
+		City:      location.City,
+	}
+}
+
+// loginClientInfo is clientInfo for sign-ins, issuing the device cookie to
+// browsers that have none yet so the next sign-in is recognised.
+func loginClientInfo(ctx *fiber.Ctx) service.ClientInfo {
+	client := clientInfo(ctx)
+	if client.Device == "" {
+		client.Device, _ = deviceFingerprint(ctx, true)
+	}
+	return client
+}
+
+// deviceFingerprint returns the hash of the device cookie. When create is
+// set and the browser has no cookie yet, a new one is issued; otherwise a
+// missing cookie yields an empty fingerprint.
+func deviceFingerprint(ctx *fiber.Ctx, create bool) (string, error) {
+	deviceId := ctx.Cookies(deviceCookie)
+	if deviceId == "" && create {
+		var err error
+		deviceId, err = helper.GenerateRandomToken(32)
+		if err != nil {
+			return "", err
+		}
+
+		ctx.Cookie(&fiber.Cookie{
+			Name:     deviceCookie,
+			Value:    deviceId,
+			Expires:  time.Now().Add(deviceCookieTTL),
+			HTTPOnly: true,
+			Secure:   true,
+			SameSite: "Lax",
+		})
+	}
+
+	if deviceId == "" {
+		return "", nil
 	}
-	return uuid.Parse(idStr)
+	return helper.HashToken(deviceId), nil
+}
+
+func userInfo(user entity.User) response.UserInfo {
+	return response.UserInfo{
+		Id:              user.Id,
+		Username:        user.Username,
+		Name:            user.Name,
+		Email:           user.Email,
+		Status:          user.Status,
+		EmailVerifiedAt: formatTime(user.EmailVerifiedAt),
+		Roles:           user.RoleNames(),
+		Permissions:     user.PermissionNames(),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// throttledResponse reports a refused login with a machine readable code
+// and the Retry-After header in seconds.
+func throttledResponse(ctx *fiber.Ctx, err *service.ThrottleError) error {
+	status, code := fiber.StatusTooManyRequests, "too_many_attempts"
+	if errors.Is(err, service.ErrAccountLocked) {
+		status, code = fiber.StatusLocked, "account_locked"
+	}
+
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+
+	return ctx.Status(status).JSON(response.JSON{
+		Status:  status,
+		Code:    code,
+		Message: err.Error(),
+	})
+}
+
+// policyResponse reports a password rejected by the password policy with
+// one entry per broken rule.
+func policyResponse(ctx *fiber.Ctx, err *password.PolicyError) error {
+	return ctx.Status(fiber.StatusUnprocessableEntity).JSON(response.JSON{
+		Status:  fiber.StatusUnprocessableEntity,
+		Code:    "password_policy",
+		Message: password.ErrPolicyViolated.Error(),
+		Errors:  err.Violations,
+	})
+}
+
+// busyResponse reports that password hashing is saturated with 503 and
+// the Retry-After header in seconds.
+func busyResponse(ctx *fiber.Ctx, err *password.SaturatedError) error {
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+
+	return ctx.Status(fiber.StatusServiceUnavailable).JSON(response.JSON{
+		Status:  fiber.StatusServiceUnavailable,
+		Code:    "busy",
+		Message: err.Error(),
+	})
 }
 
 func errorResponse(ctx *fiber.Ctx, status int, message string) error {
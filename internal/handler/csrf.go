@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CSRFHandler struct {
+	CSRF *middleware.CSRF
+}
+
+func NewCSRFHandler(csrf *middleware.CSRF) *CSRFHandler {
+	return &CSRFHandler{CSRF: csrf}
+}
+
+// CSRF Token godoc
+// @Summary Issue a CSRF token
+// @Description Set the csrf_token cookie and return its value. Requests authenticated by cookie must repeat it in the returned header on POST, PUT, PATCH and DELETE. Requests with an Authorization header are exempt.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.JSON{data=response.CSRFTokenResponse}
+// @Router /api/v1/auth/csrf [get]
+func (handler *CSRFHandler) Token(ctx *fiber.Ctx) error {
+	token, err := handler.CSRF.Issue(ctx)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("failed to issue csrf token")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to issue csrf token")
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "csrf token issued",
+		Data: response.CSRFTokenResponse{
+			Token:      token,
+			HeaderName: handler.CSRF.HeaderName(),
+		},
+	})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ImpersonationHandler struct {
+	IImpersonationService service.IImpersonationService
+}
+
+func NewImpersonationHandler(serv service.IImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{IImpersonationService: serv}
+}
+
+// Start Impersonation godoc
+// @Summary Impersonate a user
+// @Description Issue a short-lived access token acting as the user, with the admin in the act claim. The token replaces the access_token cookie; the refresh token stays the admin's, so refreshing or ending the impersonation returns to the admin.
+// @Description Every request made with the token is logged with the admin. Users who can impersonate, or who hold permissions the admin lacks, cannot be impersonated.
+// @Tags Auth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.JSON{data=response.ImpersonationResponse}
+// @Failure 400 {object} response.JSON "Bad request"
+// @Failure 401 {object} response.JSON "Unauthorized"
+// @Failure 403 {object} response.JSON "Missing permission or user cannot be impersonated"
+// @Failure 404 {object} response.JSON "User not found"
+// @Router /api/v1/auth/impersonate/{id} [post]
+func (handler *ImpersonationHandler) Start(ctx *fiber.Ctx) error {
+	principal := middleware.GetPrincipal(ctx)
+	if principal == nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+	if principal.IsApiToken() || principal.ClientId != "" || principal.IsImpersonated() {
+		return errorResponse(ctx, fiber.StatusForbidden, service.ErrImpersonationNeedsLogin.Error())
+	}
+
+	actorId, sessionId, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	userId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	result, err := handler.IImpersonationService.Start(actorId, sessionId, userId, clientInfo(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImpersonateSelf):
+			return errorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrImpersonationForbidden), errors.Is(err, service.ErrImpersonationNeedsLogin):
+			return errorResponse(ctx, fiber.StatusForbidden, err.Error())
+		default:
+			return errorResponse(ctx, fiber.StatusNotFound, "user not found")
+		}
+	}
+
+	setAccessCookie(ctx, result.AccessToken, result.ExpiresAt)
+
+	logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).Warn("impersonation started by " + principal.Username + ": " + userId.String())
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "impersonation started",
+		Data:    result,
+	})
+}
+
+// End Impersonation godoc
+// @Summary End an impersonation
+// @Description Revoke the impersonation token and return a regular access token of the admin's session, also set as the access_token cookie.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.JSON{data=response.TokenPair}
+// @Failure 400 {object} response.JSON "No impersonation is active"
+// @Failure 401 {object} response.JSON "Unauthorized or the admin's session has been revoked"
+// @Router /api/v1/auth/impersonate [delete]
+func (handler *ImpersonationHandler) End(ctx *fiber.Ctx) error {
+	principal := middleware.GetPrincipal(ctx)
+	if principal == nil || !principal.IsImpersonated() {
+		return errorResponse(ctx, fiber.StatusBadRequest, service.ErrNotImpersonating.Error())
+	}
+
+	accessToken := ctx.Cookies("access_token")
+	if accessToken == "" {
+		accessToken = middleware.FromAuthHeader("Bearer")(ctx)
+	}
+
+	token, err := handler.IImpersonationService.End(accessToken, clientInfo(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotImpersonating):
+			return errorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrSessionRevoked):
+			clearAuthCookies(ctx)
+			return errorResponse(ctx, fiber.StatusUnauthorized, err.Error())
+		}
+		logger.GetLogger().WithError(err).Error("failed to end impersonation by " + principal.ActorUsername)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to end impersonation")
+	}
+
+	setAccessCookie(ctx, token, time.Now().Add(helper.AccessTokenTTL))
+
+	logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).Warn("impersonation ended by " + principal.ActorUsername + ": " + principal.Id)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "impersonation ended",
+		Data:    response.TokenPair{AccessToken: token},
+	})
+}
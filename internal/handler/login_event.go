@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxLoginHistoryPageSize caps the page size of the login history.
+const maxLoginHistoryPageSize = 100
+
+type LoginEventHandler struct {
+	ILoginEventService service.ILoginEventService
+}
+
+func NewLoginEventHandler(serv service.ILoginEventService) *LoginEventHandler {
+	return &LoginEventHandler{ILoginEventService: serv}
+}
+
+// Login History godoc
+// @Summary List my sign-in attempts
+// @Description Successful and failed sign-ins of the authenticated user, newest first, with the IP, its network range and the user agent
+// @Tags Auth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size, at most 100"
+// @Success 200 {object} response.JSON{data=[]entity.LoginEvent} "Successfully retrieved all records."
+// @Failure 401 {object} response.JSON "Unauthorized"
+// @Router /api/v1/auth/login-history [get]
+func (handler *LoginEventHandler) FindAll(ctx *fiber.Ctx) error {
+	userId, _, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	page, pageSize, _ := helper.ParsePaginationParams(ctx)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxLoginHistoryPageSize {
+		pageSize = maxLoginHistoryPageSize
+	}
+
+	events, totalCount, err := handler.ILoginEventService.FindAllByUserId(userId, page, pageSize)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.JSON{
+			Status:  500,
+			Message: "Failed to retrieve records",
+			Errors:  err.Error(),
+		})
+	}
+
+	if totalCount == 0 || (page-1)*pageSize >= totalCount {
+		return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+			Status:  200,
+			Message: "No records found.",
+			Data:    []entity.LoginEvent{},
+		})
+	}
+
+	baseURL := ctx.Protocol() + "://" + ctx.Hostname() + ctx.Path()
+	meta := helper.GenerateMeta(baseURL, "", page, pageSize, totalCount, nil)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved all records.",
+		Data:    events,
+		Meta:    &meta,
+	})
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type LoginThrottleHandler struct {
+	ILoginThrottleService service.ILoginThrottleService
+}
+
+func NewLoginThrottleHandler(serv service.ILoginThrottleService) *LoginThrottleHandler {
+	return &LoginThrottleHandler{ILoginThrottleService: serv}
+}
+
+// Unlock User godoc
+// @Summary Unlock a user account
+// @Description Lift the lockout and reset the failed login counter of a user
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.JSON "Account has been unlocked."
+// @Failure 400 {object} response.JSON "Bad request"
+// @Failure 404 {object} response.JSON "User not found"
+// @Router /api/v1/users/{id}/unlock [post]
+func (handler *LoginThrottleHandler) Unlock(ctx *fiber.Ctx) error {
+	actorId, _, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	userId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	if err := handler.ILoginThrottleService.Unlock(userId, actorId, clientInfo(ctx)); err != nil {
+		return errorResponse(ctx, fiber.StatusNotFound, "user not found")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Account has been unlocked.",
+	})
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MagicLinkHandler struct {
+	IMagicLinkService service.IMagicLinkService
+	ITokenService     service.ITokenService
+	IMfaService       service.IMfaService
+}
+
+func NewMagicLinkHandler(serv service.IMagicLinkService, tokenServ service.ITokenService, mfaServ service.IMfaService) *MagicLinkHandler {
+	return &MagicLinkHandler{IMagicLinkService: serv, ITokenService: tokenServ, IMfaService: mfaServ}
+}
+
+// Request Magic Link godoc
+// @Summary Request a sign-in link
+// @Description Email a single-use passwordless sign-in link, valid for 15 minutes and only from the requesting browser. The response is the same whether or not the email is registered.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.MagicLinkRequest true "Magic link request"
+// @Success 202 {object} response.JSON "Sign-in link sent if the account exists"
+// @Failure 400 {object} response.JSON "Invalid request format"
+// @Failure 409 {object} response.JSON "Already authenticated"
+// @Router /api/v1/auth/magic-link [post]
+func (handler *MagicLinkHandler) Request(ctx *fiber.Ctx) error {
+	log := logger.GetLogger()
+	ip := helper.ClientIP(ctx)
+
+	var req request.MagicLinkRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	fingerprint, err := deviceFingerprint(ctx, true)
+	if err != nil {
+		log.WithField("ip", ip).WithError(err).Error("failed to issue device cookie")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to send sign-in link")
+	}
+
+	if err := handler.IMagicLinkService.Request(req, fingerprint); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+		}
+		log.WithField("ip", ip).WithError(err).Error("failed to send magic link: " + req.Email)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to send sign-in link")
+	}
+
+	log.WithField("ip", ip).Info("magic link requested: " + req.Email)
+
+	return ctx.Status(fiber.StatusAccepted).JSON(response.JSON{
+		Status:  fiber.StatusAccepted,
+		Message: "if the account exists, a sign-in link has been sent",
+	})
+}
+
+// Consume Magic Link godoc
+// @Summary Sign in with a magic link
+// @Description Exchange the token of a sign-in link for the session cookies, the same as a password login. The link must be opened in the browser it was requested from.
+// @Description Users with two-factor authentication get a challenge token instead, to be completed at /api/v1/auth/mfa/verify.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.MagicLinkConsumeRequest true "Magic link consume request"
+// @Success 200 {object} response.AuthJSON
+// @Success 202 {object} response.JSON{data=response.MfaChallengeResponse} "Two-factor authentication required"
+// @Failure 400 {object} response.JSON "Invalid request format"
+// @Failure 401 {object} response.JSON "Invalid or expired sign-in link"
+// @Failure 409 {object} response.JSON "Already authenticated"
+// @Failure 423 {object} response.JSON "Account is temporarily locked, code account_locked"
+// @Failure 429 {object} response.JSON "Too many failed attempts, code too_many_attempts"
+// @Router /api/v1/auth/magic-link/consume [post]
+func (handler *MagicLinkHandler) Consume(ctx *fiber.Ctx) error {
+	log := logger.GetLogger()
+	ip := helper.ClientIP(ctx)
+
+	var req request.MagicLinkConsumeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	fingerprint, _ := deviceFingerprint(ctx, false)
+
+	user, err := handler.IMagicLinkService.Consume(req, fingerprint, clientInfo(ctx))
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		var throttleErr *service.ThrottleError
+		switch {
+		case errors.As(err, &validationErrors):
+			return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+		case errors.As(err, &throttleErr):
+			return throttledResponse(ctx, throttleErr)
+		case errors.Is(err, service.ErrInvalidMagicLink):
+			log.WithField("ip", ip).Error("magic link sign-in failed")
+			return errorResponse(ctx, fiber.StatusUnauthorized, err.Error())
+		default:
+			log.WithField("ip", ip).WithError(err).Error("magic link sign-in failed")
+			return errorResponse(ctx, fiber.StatusInternalServerError, "failed to sign in")
+		}
+	}
+
+	if user.MfaEnabled {
+		challenge, err := handler.IMfaService.Challenge(user)
+		if err != nil {
+			log.WithField("ip", ip).WithError(err).Error("failed to issue mfa challenge: " + user.Email)
+			return errorResponse(ctx, fiber.StatusInternalServerError, "failed to issue tokens")
+		}
+
+		log.WithField("ip", ip).Info("mfa challenge issued: " + user.Email)
+
+		return ctx.Status(fiber.StatusAccepted).JSON(response.JSON{
+			Status:  fiber.StatusAccepted,
+			Message: "two-factor authentication required",
+			Data:    challenge,
+		})
+	}
+
+	tokens, err := handler.ITokenService.Issue(user, clientInfo(ctx))
+	if err != nil {
+		log.WithField("ip", ip).WithError(err).Error("failed to issue tokens: " + user.Email)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to issue tokens")
+	}
+
+	setAuthCookies(ctx, tokens.AccessToken, tokens.RefreshToken)
+
+	log.WithField("ip", ip).Info("user logged in with magic link: " + user.Email)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.AuthJSON{
+		Message:     "you are authenticated",
+		Status:      fiber.StatusOK,
+		User:        userInfo(user),
+		AccessToken: tokens.AccessToken,
+	})
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/password"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MfaHandler struct {
+	IMfaService   service.IMfaService
+	ITokenService service.ITokenService
+}
+
+func NewMfaHandler(serv service.IMfaService, tokenServ service.ITokenService) *MfaHandler {
+	return &MfaHandler{IMfaService: serv, ITokenService: tokenServ}
+}
+
+// Enroll MFA godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and otpauth URI for the authenticated user. Two-factor authentication is enabled once the secret is confirmed.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.JSON{data=response.MfaEnrollResponse} "Scan the otpauth URI with an authenticator app"
+// @Failure 401 {object} response.JSON "Unauthorized"
+// @Failure 409 {object} response.JSON "Two-factor authentication is already enabled"
+// @Router /api/v1/auth/mfa/enroll [post]
+func (handler *MfaHandler) Enroll(ctx *fiber.Ctx) error {
+	userId, _, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	result, err := handler.IMfaService.Enroll(userId)
+	if err != nil {
+		if errors.Is(err, service.ErrMfaAlreadyEnabled) {
+			return errorResponse(ctx, fiber.StatusConflict, err.Error())
+		}
+		logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to enroll mfa")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to enroll two-factor authentication")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "scan the otpauth URI with an authenticator app and confirm a code",
+		Data:    result,
+	})
+}
+
+// Confirm MFA godoc
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication with a code from the authenticator app. The returned recovery codes are shown only once.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.MfaConfirmRequest true "Confirm request"
+// @Success 200 {object} response.JSON{data=response.MfaRecoveryCodesResponse} "Two-factor authentication enabled"
+// @Failure 400 {object} response.JSON "Invalid authentication code"
+// @Failure 401 {object} response.JSON "Unauthorized"
+// @Failure 409 {object} response.JSON "Two-factor authentication is already enabled"
+// @Router /api/v1/auth/mfa/confirm [post]
+func (handler *MfaHandler) Confirm(ctx *fiber.Ctx) error {
+	userId, _, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	var req request.MfaConfirmRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	result, err := handler.IMfaService.Confirm(userId, req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+		case errors.Is(err, service.ErrInvalidMfaCode), errors.Is(err, service.ErrMfaNotEnrolled):
+			return errorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrMfaAlreadyEnabled):
+			return errorResponse(ctx, fiber.StatusConflict, err.Error())
+		default:
+			logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to confirm mfa")
+			return errorResponse(ctx, fiber.StatusInternalServerError, "failed to enable two-factor authentication")
+		}
+	}
+
+	logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).Info("mfa enabled: " + userId.String())
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "two-factor authentication has been enabled, store the recovery codes in a safe place",
+		Data:    result,
+	})
+}
+
+// Disable MFA godoc
+// @Summary Disable two-factor authentication
+// @Description Turn off two-factor authentication. Requires the password and a current code or a recovery code.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.MfaDisableRequest true "Disable request"
+// @Success 200 {object} response.JSON "Two-factor authentication disabled"
+// @Failure 400 {object} response.JSON "Invalid password or authentication code"
+// @Failure 401 {object} response.JSON "Unauthorized"
+// @Failure 503 {object} response.JSON "Password hashing is busy, see Retry-After"
+// @Router /api/v1/auth/mfa/disable [post]
+func (handler *MfaHandler) Disable(ctx *fiber.Ctx) error {
+	userId, _, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	var req request.MfaDisableRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	if err := handler.IMfaService.Disable(ctx.UserContext(), userId, req); err != nil {
+		var validationErrors validator.ValidationErrors
+		var saturatedErr *password.SaturatedError
+		switch {
+		case errors.As(err, &validationErrors):
+			return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+		case errors.Is(err, service.ErrInvalidMfaCode), errors.Is(err, service.ErrMfaNotEnabled):
+			return errorResponse(ctx, fiber.StatusBadRequest, err.Error())
+		case errors.As(err, &saturatedErr):
+			return busyResponse(ctx, saturatedErr)
+		default:
+			logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to disable mfa")
+			return errorResponse(ctx, fiber.StatusInternalServerError, "failed to disable two-factor authentication")
+		}
+	}
+
+	logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).Info("mfa disabled: " + userId.String())
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "two-factor authentication has been disabled",
+	})
+}
+
+// Verify MFA godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token returned by login and a TOTP or recovery code for the session cookies
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.MfaVerifyRequest true "Verify request"
+// @Success 200 {object} response.AuthJSON
+// @Failure 400 {object} response.JSON "Invalid request format"
+// @Failure 401 {object} response.JSON "Invalid challenge or authentication code"
+// @Failure 423 {object} response.JSON "Account is temporarily locked, code account_locked"
+// @Failure 429 {object} response.JSON "Too many failed attempts, code too_many_attempts"
+// @Router /api/v1/auth/mfa/verify [post]
+func (handler *MfaHandler) Verify(ctx *fiber.Ctx) error {
+	log := logger.GetLogger()
+	ip := helper.ClientIP(ctx)
+
+	var req request.MfaVerifyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+	}
+
+	user, err := handler.IMfaService.Verify(req, clientInfo(ctx))
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		var throttleErr *service.ThrottleError
+		switch {
+		case errors.As(err, &validationErrors):
+			return errorResponse(ctx, fiber.StatusBadRequest, "invalid request format")
+		case errors.As(err, &throttleErr):
+			log.WithField("ip", ip).Error("mfa verification throttled")
+			return throttledResponse(ctx, throttleErr)
+		case errors.Is(err, service.ErrInvalidMfaChallenge), errors.Is(err, service.ErrInvalidMfaCode):
+			log.WithField("ip", ip).Error("mfa verification failed")
+			return errorResponse(ctx, fiber.StatusUnauthorized, err.Error())
+		default:
+			log.WithField("ip", ip).WithError(err).Error("mfa verification failed")
+			return errorResponse(ctx, fiber.StatusInternalServerError, "failed to verify authentication code")
+		}
+	}
+
+	tokens, err := handler.ITokenService.Issue(user, clientInfo(ctx))
+	if err != nil {
+		log.WithField("ip", ip).WithError(err).Error("failed to issue tokens: " + user.Email)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to issue tokens")
+	}
+
+	setAuthCookies(ctx, tokens.AccessToken, tokens.RefreshToken)
+
+	log.WithField("ip", ip).Info("user logged in: " + user.Email)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.AuthJSON{
+		Message:     "you are authenticated",
+		Status:      fiber.StatusOK,
+		User:        userInfo(user),
+		AccessToken: tokens.AccessToken,
+	})
+}
@@ -0,0 +1,384 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type OAuthHandler struct {
+	IOAuthService service.IOAuthService
+}
+
+func NewOAuthHandler(serv service.IOAuthService) *OAuthHandler {
+	return &OAuthHandler{IOAuthService: serv}
+}
+
+// Authorize godoc
+// @Summary OAuth2 authorization endpoint
+// @Description Start the authorization code flow. Signed-in users are redirected back to the client with a code, others are sent to the login page first. PKCE with S256 is required.
+// @Tags OAuth
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string false "Space separated scopes, e.g. openid profile email"
+// @Param state query string false "Opaque value returned to the client"
+// @Param nonce query string false "Value echoed in the ID token"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 302 "Redirect to the client or the login page"
+// @Failure 400 {object} response.OAuthErrorResponse "Unknown client or redirect URI"
+// @Failure 403 {object} response.OAuthErrorResponse "Signed in with an impersonation token"
+// @Router /oauth/authorize [get]
+func (handler *OAuthHandler) Authorize(ctx *fiber.Ctx) error {
+	var req request.AuthorizeRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return oauthErrorResponse(ctx, fiber.StatusBadRequest, "invalid_request", "invalid query parameters")
+	}
+
+	client, err := handler.IOAuthService.ValidateAuthorization(req)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if errors.As(err, &oauthErr) {
+			return oauthErrorResponse(ctx, fiber.StatusBadRequest, oauthErr.Code, oauthErr.Description)
+		}
+		return oauthErrorResponse(ctx, fiber.StatusInternalServerError, "server_error", "")
+	}
+
+	// only the user's own session may authorize clients, not a token that
+	// was itself issued to a client or a personal access token
+	principal := middleware.GetPrincipal(ctx)
+	if principal == nil || principal.ClientId != "" || principal.IsApiToken() {
+		return ctx.Redirect(handler.IOAuthService.LoginURL(ctx.BaseURL()+ctx.OriginalURL()), fiber.StatusFound)
+	}
+	// the tokens of the client would outlive the impersonation and lose
+	// its act claim
+	if principal.IsImpersonated() {
+		return oauthErrorResponse(ctx, fiber.StatusForbidden, "access_denied", "clients cannot be authorized while impersonating")
+	}
+
+	userId, err := principal.UserId()
+	if err != nil {
+		return oauthErrorResponse(ctx, fiber.StatusUnauthorized, "access_denied", "invalid access token")
+	}
+	sessionId, _ := uuid.Parse(principal.SessionId)
+
+	redirect, err := handler.IOAuthService.Authorize(req, client, userId, sessionId)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if !errors.As(err, &oauthErr) {
+			logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to issue authorization code")
+			oauthErr = &service.OAuthError{Code: "server_error"}
+		}
+
+		return ctx.Redirect(service.AuthorizationRedirect(req.RedirectURI, map[string]string{
+			"error":             oauthErr.Code,
+			"error_description": oauthErr.Description,
+			"state":             req.State,
+		}), fiber.StatusFound)
+	}
+
+	return ctx.Redirect(redirect, fiber.StatusFound)
+}
+
+// Token godoc
+// @Summary OAuth2 token endpoint
+// @Description Exchange an authorization code, a refresh token or client credentials for tokens.
+// @Description Confidential clients authenticate with HTTP Basic or client_id and client_secret form fields.
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code, refresh_token or client_credentials"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI used to obtain the code"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Requested scope for client_credentials"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 {object} response.OAuthTokenResponse
+// @Failure 400 {object} response.OAuthErrorResponse "Invalid request or grant"
+// @Failure 401 {object} response.OAuthErrorResponse "Client authentication failed"
+// @Router /oauth/token [post]
+func (handler *OAuthHandler) Token(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderPragma, "no-cache")
+
+	var req request.TokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return oauthErrorResponse(ctx, fiber.StatusBadRequest, "invalid_request", "invalid request body")
+	}
+
+	if clientId, secret, ok := basicAuth(ctx); ok {
+		req.ClientId, req.ClientSecret = clientId, secret
+	}
+
+	result, err := handler.IOAuthService.Token(req, clientInfo(ctx))
+	if err != nil {
+		var oauthErr *service.OAuthError
+		switch {
+		case errors.Is(err, service.ErrInvalidOAuthClient):
+			ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+			return oauthErrorResponse(ctx, fiber.StatusUnauthorized, "invalid_client", "client authentication failed")
+		case errors.As(err, &oauthErr):
+			return oauthErrorResponse(ctx, fiber.StatusBadRequest, oauthErr.Code, oauthErr.Description)
+		default:
+			logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to issue oauth tokens")
+			return oauthErrorResponse(ctx, fiber.StatusInternalServerError, "server_error", "")
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
+
+// Introspect godoc
+// @Summary OAuth2 token introspection
+// @Description Report whether an access or refresh token is active (RFC 7662). Tokens that are expired, revoked or belong to a signed-out session are inactive.
+// @Description Only confidential clients may call this endpoint.
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "The token to inspect"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 {object} response.TokenIntrospectionResponse
+// @Failure 400 {object} response.OAuthErrorResponse "Invalid request"
+// @Failure 401 {object} response.OAuthErrorResponse "Client authentication failed"
+// @Router /oauth/introspect [post]
+func (handler *OAuthHandler) Introspect(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	var req request.TokenIntrospectionRequest
+	if err := ctx.BodyParser(&req); err != nil || req.Token == "" {
+		return oauthErrorResponse(ctx, fiber.StatusBadRequest, "invalid_request", "the token parameter is required")
+	}
+
+	if clientId, secret, ok := basicAuth(ctx); ok {
+		req.ClientId, req.ClientSecret = clientId, secret
+	}
+
+	result, err := handler.IOAuthService.Introspect(req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidOAuthClient) {
+			ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+			return oauthErrorResponse(ctx, fiber.StatusUnauthorized, "invalid_client", "client authentication failed")
+		}
+		logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to introspect token")
+		return oauthErrorResponse(ctx, fiber.StatusInternalServerError, "server_error", "")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
+
+// Revoke godoc
+// @Summary OAuth2 token revocation
+// @Description Revoke an access or refresh token issued to the calling client (RFC 7009). Revoking a refresh token ends its session.
+// @Description Unknown or already invalid tokens are answered with 200 as well.
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Param token formData string true "The token to revoke"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 "The token is no longer valid"
+// @Failure 400 {object} response.OAuthErrorResponse "Invalid request or token of another client"
+// @Failure 401 {object} response.OAuthErrorResponse "Client authentication failed"
+// @Router /oauth/revoke [post]
+func (handler *OAuthHandler) Revoke(ctx *fiber.Ctx) error {
+	var req request.TokenRevocationRequest
+	if err := ctx.BodyParser(&req); err != nil || req.Token == "" {
+		return oauthErrorResponse(ctx, fiber.StatusBadRequest, "invalid_request", "the token parameter is required")
+	}
+
+	if clientId, secret, ok := basicAuth(ctx); ok {
+		req.ClientId, req.ClientSecret = clientId, secret
+	}
+
+	if err := handler.IOAuthService.Revoke(req); err != nil {
+		var oauthErr *service.OAuthError
+		switch {
+		case errors.Is(err, service.ErrInvalidOAuthClient):
+			ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+			return oauthErrorResponse(ctx, fiber.StatusUnauthorized, "invalid_client", "client authentication failed")
+		case errors.As(err, &oauthErr):
+			return oauthErrorResponse(ctx, fiber.StatusBadRequest, oauthErr.Code, oauthErr.Description)
+		default:
+			logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to revoke token")
+			return oauthErrorResponse(ctx, fiber.StatusServiceUnavailable, "temporarily_unavailable", "")
+		}
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// UserInfo godoc
+// @Summary OpenID Connect userinfo endpoint
+// @Description Claims about the user of the access token, limited to the granted profile and email scopes
+// @Tags OAuth
+// @Produce json
+// @Success 200 {object} response.UserInfoResponse
+// @Failure 401 {object} response.JSON "Invalid or missing access token"
+// @Failure 403 {object} response.OAuthErrorResponse "The token was not granted the openid scope"
+// @Router /oauth/userinfo [get]
+func (handler *OAuthHandler) UserInfo(ctx *fiber.Ctx) error {
+	principal := middleware.GetPrincipal(ctx)
+	if principal == nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "access token required")
+	}
+
+	if !principal.HasScope("openid") {
+		return oauthErrorResponse(ctx, fiber.StatusForbidden, "insufficient_scope", "the openid scope is required")
+	}
+
+	scope := strings.Join(principal.Scopes, " ")
+	if principal.ClientId == "" {
+		scope = "openid profile email"
+	}
+
+	userId, err := principal.UserId()
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid user ID")
+	}
+
+	result, err := handler.IOAuthService.UserInfo(userId, scope)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "user not found")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
+
+// Create OAuth Client godoc
+// @Summary Register an OAuth client
+// @Description Register a client application. The secret of confidential clients is only returned once.
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Param request body request.OAuthClientCreateRequest true "OAuth Client Create Request"
+// @Success 201 {object} response.JSON{data=response.OAuthClientResponse} "A new record has been stored."
+// @Failure 400 {object} response.JSON "Bad request"
+// @Failure 403 {object} response.JSON "Forbidden"
+// @Router /api/v1/oauth/clients [post]
+func (handler *OAuthHandler) CreateClient(ctx *fiber.Ctx) error {
+	req := request.OAuthClientCreateRequest{}
+	if err := ctx.BodyParser(&req); err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	client, err := handler.IOAuthService.CreateClient(req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.JSON{
+				Status:  400,
+				Message: "invalid client data",
+				Errors:  err.Error(),
+			})
+		}
+		return errorResponse(ctx, fiber.StatusBadRequest, err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.JSON{
+		Status:  201,
+		Message: "A new record has been stored.",
+		Data:    client,
+	})
+}
+
+// Find All OAuth Clients godoc
+// @Summary Get all OAuth clients
+// @Description Retrieve all registered client applications
+// @Tags OAuth
+// @Produce json
+// @Success 200 {object} response.JSON "Successfully retrieved all records."
+// @Failure 403 {object} response.JSON "Forbidden"
+// @Router /api/v1/oauth/clients [get]
+func (handler *OAuthHandler) FindAllClients(ctx *fiber.Ctx) error {
+	clients, err := handler.IOAuthService.FindAllClients()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.JSON{
+			Status:  500,
+			Message: "Failed to retrieve records",
+			Errors:  err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved all records.",
+		Data:    clients,
+	})
+}
+
+// Delete OAuth Client godoc
+// @Summary Delete an OAuth client
+// @Description Remove a client application. Tokens already issued to it stay valid until they expire.
+// @Tags OAuth
+// @Produce json
+// @Param id path string true "Client record ID"
+// @Success 200 {object} response.JSON "Selected record has been deleted."
+// @Failure 404 {object} response.JSON "Not found"
+// @Router /api/v1/oauth/clients/{id} [delete]
+func (handler *OAuthHandler) DeleteClient(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	if err := handler.IOAuthService.DeleteClient(id); err != nil {
+		return errorResponse(ctx, fiber.StatusNotFound, err.Error())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Selected record has been deleted.",
+	})
+}
+
+// basicAuth reads client credentials from the Authorization header. Both
+// parts are form encoded as required by RFC 6749 section 2.3.1.
+func basicAuth(ctx *fiber.Ctx) (string, string, bool) {
+	header := ctx.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(header, "Basic ") {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Basic "))
+	if err != nil {
+		return "", "", false
+	}
+
+	id, secret, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", false
+	}
+
+	id, idErr := url.QueryUnescape(id)
+	secret, secretErr := url.QueryUnescape(secret)
+	if idErr != nil || secretErr != nil {
+		return "", "", false
+	}
+
+	return id, secret, true
+}
+
+func oauthErrorResponse(ctx *fiber.Ctx, status int, code, description string) error {
+	return ctx.Status(status).JSON(response.OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
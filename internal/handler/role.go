@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RoleHandler struct {
+	IRoleService service.IRoleService
+}
+
+func NewRoleHandler(serv service.IRoleService) *RoleHandler {
+	return &RoleHandler{IRoleService: serv}
+}
+
+// Create a New Role
+// @Summary Create role
+// @Description Store a new role with a set of permissions
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Param request body request.RoleCreateRequest true "Role Create Request"
+// @Success 201 {object} response.JSON "A new record has been stored."
+// @Failure 400 {object} response.JSON "Bad request"
+// @Failure 403 {object} response.JSON "Forbidden"
+// @Router /api/v1/roles [post]
+func (handler *RoleHandler) Create(ctx *fiber.Ctx) error {
+	req := request.RoleCreateRequest{}
+	if err := ctx.BodyParser(&req); err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	role, err := handler.IRoleService.Create(req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.JSON{
+			Status:  400,
+			Message: err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.JSON{
+		Status:  201,
+		Message: "A new record has been stored.",
+		Data:    role,
+	})
+}
+
+// Find All Roles
+// @Summary Get all roles
+// @Description Retrieve all roles with their permissions
+// @Tags Roles
+// @Produce json
+// @Success 200 {object} response.JSON "Successfully retrieved all records."
+// @Failure 403 {object} response.JSON "Forbidden"
+// @Router /api/v1/roles [get]
+func (handler *RoleHandler) FindAll(ctx *fiber.Ctx) error {
+	roles, err := handler.IRoleService.FindAll()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.JSON{
+			Status:  500,
+			Message: "Failed to retrieve records",
+			Errors:  err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved all records.",
+		Data:    roles,
+	})
+}
+
+// Find Role by Id
+// @Summary Get role by ID
+// @Description Retrieve a single role by its ID
+// @Tags Roles
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} response.JSON "Successfully retrieved selected record."
+// @Failure 404 {object} response.JSON "Role not found"
+// @Router /api/v1/roles/{id} [get]
+func (handler *RoleHandler) FindById(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	role, err := handler.IRoleService.FindById(parsedId)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(response.JSON{
+			Status:  404,
+			Message: err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved selected record.",
+		Data:    role,
+	})
+}
+
+// Update Role by Id
+// @Summary Update role
+// @Description Update a role and replace its permissions
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body request.RoleUpdateRequest true "Role Update Request"
+// @Success 200 {object} response.JSON "Selected record has been updated."
+// @Failure 400 {object} response.JSON "Bad request"
+// @Failure 404 {object} response.JSON "Role not found"
+// @Router /api/v1/roles/{id} [put]
+func (handler *RoleHandler) Update(ctx *fiber.Ctx) error {
+	req := request.RoleUpdateRequest{}
+	if err := ctx.BodyParser(&req); err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	req.Id = parsedId
+
+	role, err := handler.IRoleService.Update(req)
+	if err != nil {
+		status := fiber.StatusNotFound
+		if errors.Is(err, service.ErrUnknownPermission) {
+			status = fiber.StatusBadRequest
+		}
+		return ctx.Status(status).JSON(response.JSON{
+			Status:  status,
+			Message: err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Selected record has been updated.",
+		Data:    role,
+	})
+}
+
+// Delete Role by Id
+// @Summary Delete role
+// @Description Remove a role and unassign it from all users
+// @Tags Roles
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} response.JSON "Selected record has been deleted."
+// @Failure 404 {object} response.JSON "Role not found"
+// @Router /api/v1/roles/{id} [delete]
+func (handler *RoleHandler) Delete(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	if err := handler.IRoleService.Delete(parsedId); err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(response.JSON{
+			Status:  404,
+			Message: err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Selected record has been deleted.",
+	})
+}
+
+// Find All Permissions
+// @Summary Get all permissions
+// @Description Retrieve every permission that can be granted to a role
+// @Tags Roles
+// @Produce json
+// @Success 200 {object} response.JSON "Successfully retrieved all records."
+// @Failure 403 {object} response.JSON "Forbidden"
+// @Router /api/v1/permissions [get]
+func (handler *RoleHandler) FindAllPermissions(ctx *fiber.Ctx) error {
+	permissions, err := handler.IRoleService.FindAllPermissions()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.JSON{
+			Status:  500,
+			Message: "Failed to retrieve records",
+			Errors:  err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved all records.",
+		Data:    permissions,
+	})
+}
+
+// Assign User Roles
+// @Summary Assign roles to user
+// @Description Replace the roles of a user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body request.UserRolesRequest true "User Roles Request"
+// @Success 200 {object} response.JSON "Roles have been assigned."
+// @Failure 400 {object} response.JSON "Bad request"
+// @Router /api/v1/users/{id}/roles [put]
+func (handler *RoleHandler) AssignToUser(ctx *fiber.Ctx) error {
+	req := request.UserRolesRequest{}
+	if err := ctx.BodyParser(&req); err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	req.UserId = parsedId
+
+	if err := handler.IRoleService.AssignToUser(req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.JSON{
+			Status:  400,
+			Message: err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Roles have been assigned.",
+	})
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SessionHandler struct {
+	ISessionService service.ISessionService
+}
+
+func NewSessionHandler(serv service.ISessionService) *SessionHandler {
+	return &SessionHandler{ISessionService: serv}
+}
+
+// Find My Sessions godoc
+// @Summary List my sessions
+// @Description Retrieve the active sessions (signed-in devices) of the authenticated user
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.JSON "Successfully retrieved all records."
+// @Failure 401 {object} response.JSON "Unauthorized"
+// @Router /api/v1/auth/sessions [get]
+func (handler *SessionHandler) FindAll(ctx *fiber.Ctx) error {
+	userId, sessionId, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	sessions, err := handler.ISessionService.FindAllByUserId(userId, sessionId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.JSON{
+			Status:  500,
+			Message: "Failed to retrieve records",
+			Errors:  err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved all records.",
+		Data:    sessions,
+	})
+}
+
+// Revoke My Session godoc
+// @Summary Sign out a session
+// @Description Revoke one of the authenticated user's sessions, signing that device out
+// @Tags Auth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.JSON "Session has been revoked."
+// @Failure 404 {object} response.JSON "Session not found"
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (handler *SessionHandler) Revoke(ctx *fiber.Ctx) error {
+	userId, currentId, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	sessionId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	if err := handler.ISessionService.Revoke(userId, sessionId); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			return errorResponse(ctx, fiber.StatusNotFound, err.Error())
+		}
+		logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to revoke session")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to revoke session")
+	}
+
+	if sessionId == currentId {
+		clearAuthCookies(ctx)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Session has been revoked.",
+	})
+}
+
+// Logout Everywhere godoc
+// @Summary Log out everywhere
+// @Description Revoke every session of the authenticated user, including the current one
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.JSON "Successfully logged out from all sessions"
+// @Failure 401 {object} response.JSON "Unauthorized"
+// @Router /api/v1/auth/logout-all [post]
+func (handler *SessionHandler) RevokeAll(ctx *fiber.Ctx) error {
+	userId, _, err := currentSession(ctx)
+	if err != nil {
+		return errorResponse(ctx, fiber.StatusUnauthorized, "invalid access token")
+	}
+
+	if err := handler.ISessionService.RevokeAll(userId); err != nil {
+		logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to revoke sessions")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to log out")
+	}
+
+	clearAuthCookies(ctx)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  fiber.StatusOK,
+		Message: "successfully logged out from all sessions",
+	})
+}
+
+// Find User Sessions godoc
+// @Summary List user sessions
+// @Description Retrieve the active sessions of a user
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.JSON "Successfully retrieved all records."
+// @Failure 400 {object} response.JSON "Bad request"
+// @Router /api/v1/users/{id}/sessions [get]
+func (handler *SessionHandler) FindAllByUser(ctx *fiber.Ctx) error {
+	userId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	sessions, err := handler.ISessionService.FindAllByUserId(userId, uuid.Nil)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.JSON{
+			Status:  500,
+			Message: "Failed to retrieve records",
+			Errors:  err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "Successfully retrieved all records.",
+		Data:    sessions,
+	})
+}
+
+// Revoke User Sessions godoc
+// @Summary Sign a user out everywhere
+// @Description Revoke every session of a user
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.JSON "All sessions have been revoked."
+// @Failure 400 {object} response.JSON "Bad request"
+// @Router /api/v1/users/{id}/sessions [delete]
+func (handler *SessionHandler) RevokeAllByUser(ctx *fiber.Ctx) error {
+	userId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		helper.HandleError(ctx, fiber.StatusBadRequest, err)
+		return nil
+	}
+
+	if err := handler.ISessionService.RevokeAll(userId); err != nil {
+		logger.GetLogger().WithField("ip", helper.ClientIP(ctx)).WithError(err).Error("failed to revoke sessions")
+		return errorResponse(ctx, fiber.StatusInternalServerError, "failed to revoke sessions")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.JSON{
+		Status:  200,
+		Message: "All sessions have been revoked.",
+	})
+}
+
+// currentSession reads the user and session of the authenticated principal.
+func currentSession(ctx *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	principal := middleware.GetPrincipal(ctx)
+	if principal == nil {
+		return uuid.Nil, uuid.Nil, middleware.ErrNoToken
+	}
+
+	userId, err := principal.UserId()
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	sessionId, _ := uuid.Parse(principal.SessionId)
+	return userId, sessionId, nil
+}
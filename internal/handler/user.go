@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/fatihrizqon/go-fiber-service/helper"
 	"github.com/fatihrizqon/go-fiber-service/internal/entity"
 	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
 	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
 	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/password"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -27,6 +30,8 @@ func NewUserHandler(serv service.IUserService) *UserHandler {
 // @Param request body request.UserCreateRequest true "User Create Request"
 // @Success 201 {object} response.JSON "A new record has been stored."
 // @Failure 400 {object} response.JSON "Bad request"
+// @Failure 422 {object} response.JSON{errors=[]password.Violation} "Password rejected by the password policy"
+// @Failure 503 {object} response.JSON "Password hashing is busy, see Retry-After"
 // @Router /api/v1/users [post]
 func (handler *UserHandler) Create(ctx *fiber.Ctx) error {
 	req := request.UserCreateRequest{}
@@ -36,8 +41,16 @@ func (handler *UserHandler) Create(ctx *fiber.Ctx) error {
 		return nil
 	}
 
-	entity, err := handler.IUserService.Create(req)
+	entity, err := handler.IUserService.Create(ctx.UserContext(), req)
 	if err != nil {
+		var policyErr *password.PolicyError
+		if errors.As(err, &policyErr) {
+			return policyResponse(ctx, policyErr)
+		}
+		var saturatedErr *password.SaturatedError
+		if errors.As(err, &saturatedErr) {
+			return busyResponse(ctx, saturatedErr)
+		}
 		return ctx.Status(fiber.StatusBadRequest).JSON(response.JSON{
 			Status:  400,
 			Message: err.Error(),
@@ -145,6 +158,8 @@ func (handler *UserHandler) FindById(ctx *fiber.Ctx) error {
 // @Param request body request.UserUpdateRequest true "User Update Request"
 // @Success 200 {object} response.JSON "Selected record has been updated."
 // @Failure 404 {object} response.JSON "User not found"
+// @Failure 422 {object} response.JSON{errors=[]password.Violation} "Password rejected by the password policy"
+// @Failure 503 {object} response.JSON "Password hashing is busy, see Retry-After"
 // @Router /api/v1/users/{id} [put]
 func (handler *UserHandler) Update(ctx *fiber.Ctx) error {
 	req := request.UserUpdateRequest{}
@@ -163,8 +178,16 @@ func (handler *UserHandler) Update(ctx *fiber.Ctx) error {
 
 	req.Id = parsedId
 
-	entity, err := handler.IUserService.Update(req)
+	entity, err := handler.IUserService.Update(ctx.UserContext(), req)
 	if err != nil {
+		var policyErr *password.PolicyError
+		if errors.As(err, &policyErr) {
+			return policyResponse(ctx, policyErr)
+		}
+		var saturatedErr *password.SaturatedError
+		if errors.As(err, &saturatedErr) {
+			return busyResponse(ctx, saturatedErr)
+		}
 		return ctx.Status(fiber.StatusNotFound).JSON(response.JSON{
 			Status:  404,
 			Message: err.Error(),
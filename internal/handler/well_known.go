@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WellKnownHandler struct {
+	IOAuthService service.IOAuthService
+}
+
+func NewWellKnownHandler(oauthServ service.IOAuthService) *WellKnownHandler {
+	return &WellKnownHandler{IOAuthService: oauthServ}
+}
+
+// OpenID Configuration godoc
+// @Summary OpenID Connect discovery
+// @Description Provider metadata listing the OAuth endpoints and supported features
+// @Tags Well-Known
+// @Produce json
+// @Success 200 {object} response.OpenIDConfiguration
+// @Router /.well-known/openid-configuration [get]
+func (handler *WellKnownHandler) OpenIDConfiguration(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.Status(fiber.StatusOK).JSON(handler.IOAuthService.Discovery())
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys verifying access tokens, selected by the kid header of the token. Empty when tokens are signed with a shared secret.
+// @Tags Well-Known
+// @Produce json
+// @Success 200 {object} helper.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (handler *WellKnownHandler) JWKS(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.Status(fiber.StatusOK).JSON(helper.AccessKeySet().JWKS())
+}
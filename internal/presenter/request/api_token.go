@@ -0,0 +1,10 @@
+package request
+
+import "github.com/google/uuid"
+
+type ApiTokenCreateRequest struct {
+	UserId        uuid.UUID
+	Name          string   `validate:"required,min=1,max=100" json:"name" example:"CI deploy"`
+	Scopes        []string `json:"scopes" example:"users.read"`
+	ExpiresInDays int      `validate:"omitempty,min=1,max=365" json:"expires_in_days" example:"90"`
+}
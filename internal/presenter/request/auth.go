@@ -2,11 +2,37 @@ package request
 
 type LoginRequest struct {
 	Email    string `validate:"required,min=1,max=20" json:"email" example:"johndoe@example.com"`
-	Password string `validate:"required,min=8" json:"password" example:"yoursecretpassword"`
+	Password string `validate:"required" json:"password" example:"yoursecretpassword"`
 }
 
 type RegisterRequest struct {
-	Username string `validate:"required,min=1,max=20" json:"username"`
-	Email    string `validate:"required,min=1" json:"email"`
-	Password string `validate:"required,min=8" json:"password"`
+	Username string `validate:"required,min=1,max=20" json:"username" example:"johndoe"`
+	Name     string `validate:"required,min=1" json:"name" example:"John Doe"`
+	Email    string `validate:"required,email" json:"email" example:"johndoe@example.com"`
+	Password string `validate:"required" json:"password" example:"yoursecretpassword"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `validate:"required" json:"token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `validate:"required,email" json:"email" example:"johndoe@example.com"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `validate:"required,email" json:"email" example:"johndoe@example.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `validate:"required" json:"token"`
+	Password string `validate:"required" json:"password" example:"yournewsecretpassword"`
+}
+
+type MagicLinkRequest struct {
+	Email string `validate:"required,email" json:"email" example:"johndoe@example.com"`
+}
+
+type MagicLinkConsumeRequest struct {
+	Token string `validate:"required" json:"token"`
 }
@@ -0,0 +1,15 @@
+package request
+
+type MfaConfirmRequest struct {
+	Code string `validate:"required" json:"code" example:"123456"`
+}
+
+type MfaDisableRequest struct {
+	Password string `validate:"required" json:"password" example:"yoursecretpassword"`
+	Code     string `validate:"required" json:"code" example:"123456"`
+}
+
+type MfaVerifyRequest struct {
+	ChallengeToken string `validate:"required" json:"challenge_token"`
+	Code           string `validate:"required" json:"code" example:"123456"`
+}
@@ -0,0 +1,51 @@
+package request
+
+type OAuthClientCreateRequest struct {
+	Name         string   `validate:"required,min=1" json:"name" example:"Mobile App"`
+	RedirectURIs []string `validate:"dive,url" json:"redirect_uris" example:"com.example.app:/callback"`
+	Scopes       []string `json:"scopes" example:"openid,profile,email"`
+	GrantTypes   []string `validate:"required,min=1,dive,oneof=authorization_code refresh_token client_credentials" json:"grant_types" example:"authorization_code,refresh_token"`
+	Confidential bool     `json:"confidential"`
+}
+
+type AuthorizeRequest struct {
+	ResponseType        string `query:"response_type"`
+	ClientId            string `query:"client_id"`
+	RedirectURI         string `query:"redirect_uri"`
+	Scope               string `query:"scope"`
+	State               string `query:"state"`
+	Nonce               string `query:"nonce"`
+	CodeChallenge       string `query:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method"`
+}
+
+// TokenRequest is the form posted to the token endpoint. The client may
+// authenticate with HTTP Basic instead of ClientId and ClientSecret.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientId     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// TokenIntrospectionRequest is the form posted to the introspection
+// endpoint (RFC 7662). The caller authenticates like at the token endpoint.
+type TokenIntrospectionRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientId      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// TokenRevocationRequest is the form posted to the revocation endpoint
+// (RFC 7009).
+type TokenRevocationRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientId      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
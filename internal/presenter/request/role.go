@@ -0,0 +1,21 @@
+package request
+
+import "github.com/google/uuid"
+
+type RoleCreateRequest struct {
+	Name        string   `validate:"required,min=1,max=50" json:"name" example:"support"`
+	Description string   `json:"description" example:"Customer support staff"`
+	Permissions []string `json:"permissions" example:"users.read"`
+}
+
+type RoleUpdateRequest struct {
+	Id          uuid.UUID
+	Name        string   `validate:"required,min=1,max=50" json:"name" example:"support"`
+	Description string   `json:"description" example:"Customer support staff"`
+	Permissions []string `json:"permissions" example:"users.read"`
+}
+
+type UserRolesRequest struct {
+	UserId uuid.UUID
+	Roles  []string `json:"roles" example:"admin"`
+}
@@ -6,7 +6,7 @@ type UserCreateRequest struct {
 	Username string `validate:"required,min=1,max=20" json:"username"`
 	Name     string `validate:"required,min=1" json:"name"`
 	Email    string `validate:"required,min=1" json:"email"`
-	Password string `validate:"required,min=8" json:"password"`
+	Password string `validate:"required" json:"password"`
 }
 
 type UserUpdateRequest struct {
@@ -14,5 +14,5 @@ type UserUpdateRequest struct {
 	Username string `validate:"required,min=1,max=20" json:"username"`
 	Name     string `validate:"required,min=1,max=20" json:"name"`
 	Email    string `validate:"required,min=1" json:"email"`
-	Password string `validate:"min=8" json:"password"`
+	Password string `json:"password"`
 }
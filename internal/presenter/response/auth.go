@@ -17,6 +17,35 @@ type RegisterResponse struct {
 }
 
 type LoginResponse struct {
-	Token string      `json:"token"`
-	User  entity.User `json:"user"`
+	User entity.User `json:"user"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type SessionResponse struct {
+	Id         uuid.UUID `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Country    string    `json:"country"`
+	City       string    `json:"city"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type CSRFTokenResponse struct {
+	Token      string `json:"csrf_token"`
+	HeaderName string `json:"header_name" example:"X-CSRF-Token"`
+}
+
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UserId      uuid.UUID `json:"user_id"`
+	ActorId     uuid.UUID `json:"actor_id"`
 }
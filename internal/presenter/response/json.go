@@ -7,6 +7,13 @@ type AuthJSON struct {
 	Message     string   `json:"message"`
 	User        UserInfo `json:"user"`
 	AccessToken string   `json:"access_token"`
+	// Impersonator is set while an admin is acting as the user
+	Impersonator *Impersonator `json:"impersonator,omitempty"`
+}
+
+type Impersonator struct {
+	Id       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
 }
 
 type UserInfo struct {
@@ -26,6 +33,7 @@ type SelectJSON struct {
 
 type JSON struct {
 	Status  int         `json:"status"`
+	Code    string      `json:"code,omitempty"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 	Meta    *Meta       `json:"meta,omitempty"`
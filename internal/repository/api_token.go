@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IApiTokenRepository interface {
+	Create(entity entity.ApiToken) (entity.ApiToken, error)
+	FindAllByUserId(userId uuid.UUID) ([]entity.ApiToken, error)
+	FindById(entityId uuid.UUID) (entity.ApiToken, error)
+	FindByHash(tokenHash string) (entity.ApiToken, error)
+	Touch(entityId uuid.UUID, usedAt time.Time) error
+	Delete(entityId uuid.UUID) error
+}
+
+type ApiTokenRepository struct {
+	Db *gorm.DB
+}
+
+func NewApiTokenRepository(Db *gorm.DB) IApiTokenRepository {
+	return &ApiTokenRepository{Db: Db}
+}
+
+// Create implements IApiTokenRepository.
+func (e *ApiTokenRepository) Create(entity entity.ApiToken) (entity.ApiToken, error) {
+	if err := e.Db.Create(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindAllByUserId implements IApiTokenRepository.
+func (e *ApiTokenRepository) FindAllByUserId(userId uuid.UUID) ([]entity.ApiToken, error) {
+	var entities []entity.ApiToken
+	if err := e.Db.Where("user_id = ?", userId).Order("created_at DESC").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindById implements IApiTokenRepository.
+func (e *ApiTokenRepository) FindById(entityId uuid.UUID) (entity.ApiToken, error) {
+	var entity entity.ApiToken
+	if err := e.Db.Where("id = ?", entityId).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindByHash implements IApiTokenRepository.
+func (e *ApiTokenRepository) FindByHash(tokenHash string) (entity.ApiToken, error) {
+	var entity entity.ApiToken
+	if err := e.Db.Where("token_hash = ?", tokenHash).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// Touch implements IApiTokenRepository.
+func (e *ApiTokenRepository) Touch(entityId uuid.UUID, usedAt time.Time) error {
+	return e.Db.Model(&entity.ApiToken{}).Where("id = ?", entityId).Update("last_used_at", usedAt).Error
+}
+
+// Delete implements IApiTokenRepository.
+func (e *ApiTokenRepository) Delete(entityId uuid.UUID) error {
+	result := e.Db.Where("id = ?", entityId).Delete(&entity.ApiToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
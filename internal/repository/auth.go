@@ -2,14 +2,29 @@ package repository
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrDuplicateUsername = errors.New("username already exists")
+	ErrDuplicateEmail    = errors.New("email already exists")
+)
+
 type IAuthRepository interface {
 	Register(entity entity.User) (entity.User, error)
 	Login(username string) (entity.User, error)
+	ExistsByUsername(username string) (bool, error)
+	ExistsByEmail(email string) (bool, error)
+	FindById(entityId uuid.UUID) (entity.User, error)
+	FindByEmail(email string) (entity.User, error)
+	MarkEmailVerified(entityId uuid.UUID, verifiedAt time.Time) error
+	UpdatePassword(entityId uuid.UUID, password string) error
 }
 
 type AuthRepository struct {
@@ -20,16 +35,91 @@ func NewAuthRepository(Db *gorm.DB) IAuthRepository {
 	return &AuthRepository{Db: Db}
 }
 
-// Register implements IAuthRepository.
+// Register implements IAuthRepository. A username or email taken by a
+// concurrent registration fails with ErrDuplicateUsername or
+// ErrDuplicateEmail.
 func (e *AuthRepository) Register(entity entity.User) (entity.User, error) {
-	panic("unimplemented")
+	tx := e.Db.Begin()
+
+	if err := tx.Create(&entity).Error; err != nil {
+		tx.Rollback()
+		return entity, uniqueViolation(err)
+	}
+
+	tx.Commit()
+	return entity, nil
 }
 
 // Login implements IAuthRepository.
 func (e *AuthRepository) Login(email string) (entity.User, error) {
 	var entity entity.User
-	if err := e.Db.Where("email = ?", email).First(&entity).Error; err != nil {
+	if err := e.Db.Preload("Roles.Permissions").Where("email = ?", email).First(&entity).Error; err != nil {
 		return entity, errors.New("credentials does not matches our record")
 	}
 	return entity, nil
 }
+
+// FindById implements IAuthRepository.
+func (e *AuthRepository) FindById(entityId uuid.UUID) (entity.User, error) {
+	var entity entity.User
+	if err := e.Db.Preload("Roles.Permissions").Where("id = ?", entityId).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindByEmail implements IAuthRepository.
+func (e *AuthRepository) FindByEmail(email string) (entity.User, error) {
+	var entity entity.User
+	if err := e.Db.Where("email = ?", email).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// MarkEmailVerified implements IAuthRepository.
+func (e *AuthRepository) MarkEmailVerified(entityId uuid.UUID, verifiedAt time.Time) error {
+	return e.Db.Model(&entity.User{}).Where("id = ?", entityId).Update("email_verified_at", verifiedAt).Error
+}
+
+// UpdatePassword implements IAuthRepository.
+func (e *AuthRepository) UpdatePassword(entityId uuid.UUID, password string) error {
+	return e.Db.Model(&entity.User{}).Where("id = ?", entityId).Update("password", password).Error
+}
+
+// ExistsByUsername implements IAuthRepository.
+func (e *AuthRepository) ExistsByUsername(username string) (bool, error) {
+	var count int64
+	if err := e.Db.Model(&entity.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ExistsByEmail implements IAuthRepository.
+func (e *AuthRepository) ExistsByEmail(email string) (bool, error) {
+	var count int64
+	if err := e.Db.Model(&entity.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// uniqueViolation maps the unique_violation of the users table to
+// ErrDuplicateUsername or ErrDuplicateEmail, other errors are returned
+// as they are.
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return err
+	}
+
+	switch {
+	case strings.Contains(pgErr.ConstraintName, "email"):
+		return ErrDuplicateEmail
+	case strings.Contains(pgErr.ConstraintName, "username"):
+		return ErrDuplicateUsername
+	default:
+		return err
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ILoginEventRepository interface {
+	Create(entity entity.LoginEvent) (entity.LoginEvent, error)
+	FindAllByUserId(userId uuid.UUID, page, pageSize int) ([]entity.LoginEvent, int, error)
+	CountSuccesses(userId uuid.UUID, device, network string) (int64, error)
+}
+
+type LoginEventRepository struct {
+	Db *gorm.DB
+}
+
+func NewLoginEventRepository(Db *gorm.DB) ILoginEventRepository {
+	return &LoginEventRepository{Db: Db}
+}
+
+// Create implements ILoginEventRepository.
+func (e *LoginEventRepository) Create(entity entity.LoginEvent) (entity.LoginEvent, error) {
+	if err := e.Db.Create(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindAllByUserId implements ILoginEventRepository, newest first.
+func (e *LoginEventRepository) FindAllByUserId(userId uuid.UUID, page, pageSize int) ([]entity.LoginEvent, int, error) {
+	var entities []entity.LoginEvent
+	var totalCount int64
+
+	query := e.Db.Model(&entity.LoginEvent{}).Where("user_id = ?", userId)
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at desc").Offset(offset).Limit(pageSize).Find(&entities).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entities, int(totalCount), nil
+}
+
+// CountSuccesses implements ILoginEventRepository. Empty device or network
+// values do not filter.
+func (e *LoginEventRepository) CountSuccesses(userId uuid.UUID, device, network string) (int64, error) {
+	var count int64
+
+	query := e.Db.Model(&entity.LoginEvent{}).Where("user_id = ? AND success", userId)
+	if device != "" {
+		query = query.Where("device = ?", device)
+	}
+	if network != "" {
+		query = query.Where("network = ?", network)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ILoginThrottleRepository interface {
+	Find(key string) (entity.LoginThrottle, error)
+	RecordFailure(key string, failedAt, since time.Time) (entity.LoginThrottle, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+	DeleteStale(before, now time.Time) (int64, error)
+}
+
+type LoginThrottleRepository struct {
+	Db *gorm.DB
+}
+
+func NewLoginThrottleRepository(Db *gorm.DB) ILoginThrottleRepository {
+	return &LoginThrottleRepository{Db: Db}
+}
+
+// Find implements ILoginThrottleRepository. Unknown keys return an empty
+// throttle without error.
+func (e *LoginThrottleRepository) Find(key string) (entity.LoginThrottle, error) {
+	var entity entity.LoginThrottle
+	err := e.Db.Where("key = ?", key).First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		entity.Key = key
+		return entity, nil
+	}
+	return entity, err
+}
+
+// RecordFailure implements ILoginThrottleRepository. The counter is
+// incremented in the database so concurrent attempts are all counted. A
+// counter whose last failure is older than since starts over.
+func (e *LoginThrottleRepository) RecordFailure(key string, failedAt, since time.Time) (entity.LoginThrottle, error) {
+	err := e.Db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END", since),
+			"last_failure_at": failedAt,
+			"updated_at":      failedAt,
+		}),
+	}).Create(&entity.LoginThrottle{
+		Key:           key,
+		Failures:      1,
+		LastFailureAt: failedAt,
+	}).Error
+	if err != nil {
+		return entity.LoginThrottle{}, err
+	}
+
+	return e.Find(key)
+}
+
+// Lock implements ILoginThrottleRepository. The failures are cleared, so
+// once the lock expires the account gets a full set of attempts again.
+func (e *LoginThrottleRepository) Lock(key string, until time.Time) error {
+	return e.Db.Model(&entity.LoginThrottle{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"locked_until": until,
+		"failures":     0,
+	}).Error
+}
+
+// Reset implements ILoginThrottleRepository.
+func (e *LoginThrottleRepository) Reset(key string) error {
+	return e.Db.Where("key = ?", key).Delete(&entity.LoginThrottle{}).Error
+}
+
+// DeleteStale implements ILoginThrottleRepository. It deletes the counters
+// whose last failure is older than before, unless they are still locked
+// at now.
+func (e *LoginThrottleRepository) DeleteStale(before, now time.Time) (int64, error) {
+	result := e.Db.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, now).Delete(&entity.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
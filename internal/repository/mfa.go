@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IMfaRepository interface {
+	SaveSecret(userId uuid.UUID, secret string) error
+	Enable(userId uuid.UUID, codes []entity.MfaRecoveryCode) error
+	Disable(userId uuid.UUID) error
+	UseStep(userId uuid.UUID, step int64) (bool, error)
+	ConsumeRecoveryCode(userId uuid.UUID, codeHash string, usedAt time.Time) (bool, error)
+	CountRecoveryCodes(userId uuid.UUID) (int64, error)
+}
+
+type MfaRepository struct {
+	Db *gorm.DB
+}
+
+func NewMfaRepository(Db *gorm.DB) IMfaRepository {
+	return &MfaRepository{Db: Db}
+}
+
+// SaveSecret implements IMfaRepository. It stores a pending secret, MFA
+// stays disabled until the secret is confirmed.
+func (e *MfaRepository) SaveSecret(userId uuid.UUID, secret string) error {
+	return e.Db.Model(&entity.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+		"mfa_secret":    secret,
+		"mfa_enabled":   false,
+		"mfa_last_step": 0,
+	}).Error
+}
+
+// Enable implements IMfaRepository. Previous recovery codes are replaced.
+func (e *MfaRepository) Enable(userId uuid.UUID, codes []entity.MfaRecoveryCode) error {
+	return e.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.User{}).Where("id = ?", userId).Update("mfa_enabled", true).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", userId).Delete(&entity.MfaRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+// Disable implements IMfaRepository.
+func (e *MfaRepository) Disable(userId uuid.UUID) error {
+	return e.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+			"mfa_secret":    "",
+			"mfa_enabled":   false,
+			"mfa_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userId).Delete(&entity.MfaRecoveryCode{}).Error
+	})
+}
+
+// UseStep implements IMfaRepository. It records the TOTP time step as used
+// only if it is newer than the last one, so a code cannot be replayed.
+func (e *MfaRepository) UseStep(userId uuid.UUID, step int64) (bool, error) {
+	result := e.Db.Model(&entity.User{}).
+		Where("id = ? AND mfa_last_step < ?", userId, step).
+		Update("mfa_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ConsumeRecoveryCode implements IMfaRepository.
+func (e *MfaRepository) ConsumeRecoveryCode(userId uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	result := e.Db.Model(&entity.MfaRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CountRecoveryCodes implements IMfaRepository. Only unused codes count.
+func (e *MfaRepository) CountRecoveryCodes(userId uuid.UUID) (int64, error) {
+	var count int64
+	if err := e.Db.Model(&entity.MfaRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IOAuthRepository interface {
+	CreateClient(entity entity.OAuthClient) (entity.OAuthClient, error)
+	FindAllClients() ([]entity.OAuthClient, error)
+	FindClientByClientId(clientId string) (entity.OAuthClient, error)
+	DeleteClient(entityId uuid.UUID) error
+	CreateCode(entity entity.OAuthAuthorizationCode) (entity.OAuthAuthorizationCode, error)
+	FindCodeByHash(codeHash string) (entity.OAuthAuthorizationCode, error)
+	ConsumeCode(entityId uuid.UUID, usedAt time.Time) (bool, error)
+}
+
+type OAuthRepository struct {
+	Db *gorm.DB
+}
+
+func NewOAuthRepository(Db *gorm.DB) IOAuthRepository {
+	return &OAuthRepository{Db: Db}
+}
+
+// CreateClient implements IOAuthRepository.
+func (e *OAuthRepository) CreateClient(entity entity.OAuthClient) (entity.OAuthClient, error) {
+	if err := e.Db.Create(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindAllClients implements IOAuthRepository.
+func (e *OAuthRepository) FindAllClients() ([]entity.OAuthClient, error) {
+	var entities []entity.OAuthClient
+	if err := e.Db.Order("created_at").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindClientByClientId implements IOAuthRepository.
+func (e *OAuthRepository) FindClientByClientId(clientId string) (entity.OAuthClient, error) {
+	var entity entity.OAuthClient
+	if err := e.Db.Where("client_id = ?", clientId).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// DeleteClient implements IOAuthRepository.
+func (e *OAuthRepository) DeleteClient(entityId uuid.UUID) error {
+	result := e.Db.Where("id = ?", entityId).Delete(&entity.OAuthClient{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateCode implements IOAuthRepository.
+func (e *OAuthRepository) CreateCode(entity entity.OAuthAuthorizationCode) (entity.OAuthAuthorizationCode, error) {
+	if err := e.Db.Create(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindCodeByHash implements IOAuthRepository.
+func (e *OAuthRepository) FindCodeByHash(codeHash string) (entity.OAuthAuthorizationCode, error) {
+	var entity entity.OAuthAuthorizationCode
+	if err := e.Db.Where("code_hash = ?", codeHash).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// ConsumeCode implements IOAuthRepository. It marks the code as used only
+// if nobody did so before, reporting whether this call won.
+func (e *OAuthRepository) ConsumeCode(entityId uuid.UUID, usedAt time.Time) (bool, error) {
+	result := e.Db.Model(&entity.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", entityId).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
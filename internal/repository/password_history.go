@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IPasswordHistoryRepository interface {
+	Create(entity entity.PasswordHistory) error
+	FindRecentByUserId(userId uuid.UUID, limit int) ([]entity.PasswordHistory, error)
+	Prune(userId uuid.UUID, keep int) error
+}
+
+type PasswordHistoryRepository struct {
+	Db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(Db *gorm.DB) IPasswordHistoryRepository {
+	return &PasswordHistoryRepository{Db: Db}
+}
+
+// Create implements IPasswordHistoryRepository.
+func (e *PasswordHistoryRepository) Create(entity entity.PasswordHistory) error {
+	return e.Db.Create(&entity).Error
+}
+
+// FindRecentByUserId implements IPasswordHistoryRepository.
+func (e *PasswordHistoryRepository) FindRecentByUserId(userId uuid.UUID, limit int) ([]entity.PasswordHistory, error) {
+	var entities []entity.PasswordHistory
+	err := e.Db.Where("user_id = ?", userId).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entities).Error
+	return entities, err
+}
+
+// Prune implements IPasswordHistoryRepository. It deletes all but the keep
+// most recent entries of the user.
+func (e *PasswordHistoryRepository) Prune(userId uuid.UUID, keep int) error {
+	recent := e.Db.Model(&entity.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userId).
+		Order("created_at DESC").
+		Limit(keep)
+
+	return e.Db.Where("user_id = ? AND id NOT IN (?)", userId, recent).
+		Delete(&entity.PasswordHistory{}).Error
+}
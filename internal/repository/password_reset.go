@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IPasswordResetRepository interface {
+	Create(entity entity.PasswordReset) (entity.PasswordReset, error)
+	FindByTokenHash(tokenHash string) (entity.PasswordReset, error)
+	Consume(entityId uuid.UUID, usedAt time.Time) (bool, error)
+	DeleteByUserId(userId uuid.UUID) error
+}
+
+type PasswordResetRepository struct {
+	Db *gorm.DB
+}
+
+func NewPasswordResetRepository(Db *gorm.DB) IPasswordResetRepository {
+	return &PasswordResetRepository{Db: Db}
+}
+
+// Create implements IPasswordResetRepository.
+func (e *PasswordResetRepository) Create(entity entity.PasswordReset) (entity.PasswordReset, error) {
+	if err := e.Db.Create(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindByTokenHash implements IPasswordResetRepository.
+func (e *PasswordResetRepository) FindByTokenHash(tokenHash string) (entity.PasswordReset, error) {
+	var entity entity.PasswordReset
+	if err := e.Db.Where("token_hash = ?", tokenHash).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// Consume implements IPasswordResetRepository. It marks the token as used
+// only if nobody did so before, reporting whether this call won.
+func (e *PasswordResetRepository) Consume(entityId uuid.UUID, usedAt time.Time) (bool, error) {
+	result := e.Db.Model(&entity.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", entityId).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteByUserId implements IPasswordResetRepository.
+func (e *PasswordResetRepository) DeleteByUserId(userId uuid.UUID) error {
+	return e.Db.Where("user_id = ?", userId).Delete(&entity.PasswordReset{}).Error
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IRoleRepository interface {
+	Create(entity.Role) (entity.Role, error)
+	FindAll() ([]entity.Role, error)
+	FindById(entityId uuid.UUID) (entity.Role, error)
+	FindByNames(names []string) ([]entity.Role, error)
+	Update(entity.Role) error
+	Delete(entityId uuid.UUID) error
+	FindAllPermissions() ([]entity.Permission, error)
+	FindPermissionsByNames(names []string) ([]entity.Permission, error)
+	AssignToUser(userId uuid.UUID, roles []entity.Role) error
+}
+
+type RoleRepository struct {
+	Db *gorm.DB
+}
+
+func NewRoleRepository(Db *gorm.DB) IRoleRepository {
+	return &RoleRepository{Db: Db}
+}
+
+// Create implements IRoleRepository.
+func (e *RoleRepository) Create(entity entity.Role) (entity.Role, error) {
+	tx := e.Db.Begin()
+
+	if err := tx.Create(&entity).Error; err != nil {
+		tx.Rollback()
+		return entity, err
+	}
+
+	tx.Commit()
+	return entity, nil
+}
+
+// FindAll implements IRoleRepository.
+func (e *RoleRepository) FindAll() ([]entity.Role, error) {
+	var entities []entity.Role
+	if err := e.Db.Preload("Permissions").Order("name ASC").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindById implements IRoleRepository.
+func (e *RoleRepository) FindById(entityId uuid.UUID) (entity.Role, error) {
+	var entity entity.Role
+	if err := e.Db.Preload("Permissions").Where("id = ?", entityId).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindByNames implements IRoleRepository.
+func (e *RoleRepository) FindByNames(names []string) ([]entity.Role, error) {
+	var entities []entity.Role
+	if err := e.Db.Where("name IN ?", names).Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Update implements IRoleRepository. The role's permissions are replaced by
+// entity.Permissions.
+func (e *RoleRepository) Update(entity entity.Role) error {
+	tx := e.Db.Begin()
+
+	if err := tx.Model(&entity).Omit("Permissions").Updates(map[string]interface{}{
+		"name":        entity.Name,
+		"description": entity.Description,
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&entity).Association("Permissions").Replace(entity.Permissions); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+// Delete implements IRoleRepository.
+func (e *RoleRepository) Delete(entityId uuid.UUID) error {
+	entity := entity.Role{Id: entityId}
+	tx := e.Db.Begin()
+
+	if err := tx.Model(&entity).Association("Permissions").Clear(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", entityId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Delete(&entity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+	return nil
+}
+
+// FindAllPermissions implements IRoleRepository.
+func (e *RoleRepository) FindAllPermissions() ([]entity.Permission, error) {
+	var entities []entity.Permission
+	if err := e.Db.Order("name ASC").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindPermissionsByNames implements IRoleRepository.
+func (e *RoleRepository) FindPermissionsByNames(names []string) ([]entity.Permission, error) {
+	var entities []entity.Permission
+	if err := e.Db.Where("name IN ?", names).Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// AssignToUser implements IRoleRepository. The user's roles are replaced.
+func (e *RoleRepository) AssignToUser(userId uuid.UUID, roles []entity.Role) error {
+	user := entity.User{Id: userId}
+	return e.Db.Model(&user).Association("Roles").Replace(roles)
+}
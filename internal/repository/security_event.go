@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"gorm.io/gorm"
+)
+
+type ISecurityEventRepository interface {
+	Create(entity entity.SecurityEvent) (entity.SecurityEvent, error)
+}
+
+type SecurityEventRepository struct {
+	Db *gorm.DB
+}
+
+func NewSecurityEventRepository(Db *gorm.DB) ISecurityEventRepository {
+	return &SecurityEventRepository{Db: Db}
+}
+
+// Create implements ISecurityEventRepository.
+func (e *SecurityEventRepository) Create(entity entity.SecurityEvent) (entity.SecurityEvent, error) {
+	if err := e.Db.Create(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
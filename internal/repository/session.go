@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ISessionRepository interface {
+	Create(entity entity.Session) (entity.Session, error)
+	FindById(entityId uuid.UUID) (entity.Session, error)
+	FindActiveByUserId(userId uuid.UUID) ([]entity.Session, error)
+	Touch(entityId uuid.UUID, seenAt, expiresAt time.Time) error
+	Revoke(entityId uuid.UUID, revokedAt time.Time) error
+	RevokeAllByUserId(userId uuid.UUID, revokedAt time.Time) error
+}
+
+type SessionRepository struct {
+	Db *gorm.DB
+}
+
+func NewSessionRepository(Db *gorm.DB) ISessionRepository {
+	return &SessionRepository{Db: Db}
+}
+
+// Create implements ISessionRepository.
+func (e *SessionRepository) Create(entity entity.Session) (entity.Session, error) {
+	if err := e.Db.Create(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindById implements ISessionRepository.
+func (e *SessionRepository) FindById(entityId uuid.UUID) (entity.Session, error) {
+	var entity entity.Session
+	if err := e.Db.Where("id = ?", entityId).First(&entity).Error; err != nil {
+		return entity, err
+	}
+	return entity, nil
+}
+
+// FindActiveByUserId implements ISessionRepository.
+func (e *SessionRepository) FindActiveByUserId(userId uuid.UUID) ([]entity.Session, error) {
+	var entities []entity.Session
+	err := e.Db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&entities).Error
+	return entities, err
+}
+
+// Touch implements ISessionRepository.
+func (e *SessionRepository) Touch(entityId uuid.UUID, seenAt, expiresAt time.Time) error {
+	return e.Db.Model(&entity.Session{}).Where("id = ?", entityId).Updates(map[string]interface{}{
+		"last_seen_at": seenAt,
+		"expires_at":   expiresAt,
+	}).Error
+}
+
+// Revoke implements ISessionRepository.
+func (e *SessionRepository) Revoke(entityId uuid.UUID, revokedAt time.Time) error {
+	return e.Db.Model(&entity.Session{}).
+		Where("id = ? AND revoked_at IS NULL", entityId).
+		Update("revoked_at", revokedAt).Error
+}
+
+// RevokeAllByUserId implements ISessionRepository.
+func (e *SessionRepository) RevokeAllByUserId(userId uuid.UUID, revokedAt time.Time) error {
+	return e.Db.Model(&entity.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", revokedAt).Error
+}
@@ -15,6 +15,7 @@ type IUserRepository interface {
 	FindById(entityId uuid.UUID) (entity.User, error)
 	Update(entity.User) error
 	Delete(entityId uuid.UUID) error
+	ResetEmailVerification(entityId uuid.UUID) error
 }
 
 type UserRepository struct {
@@ -100,6 +101,11 @@ func (e *UserRepository) Update(entity entity.User) error {
 	return nil
 }
 
+// ResetEmailVerification implements IUserRepository.
+func (e *UserRepository) ResetEmailVerification(entityId uuid.UUID) error {
+	return e.Db.Model(&entity.User{}).Where("id = ?", entityId).Update("email_verified_at", nil).Error
+}
+
 // Delete implements IUserRepository.
 func (e *UserRepository) Delete(entityId uuid.UUID) error {
 	var entity entity.User
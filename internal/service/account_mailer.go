@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/geoip"
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/mailer"
+)
+
+// AccountMailer composes the account related emails and hands them to the
+// configured mail backend. Links point at the frontend, which forwards the
+// token to the matching API endpoint.
+type AccountMailer struct {
+	Mailer      mailer.Mailer
+	FrontendURL string
+}
+
+func NewAccountMailer(m mailer.Mailer, frontendURL string) *AccountMailer {
+	return &AccountMailer{
+		Mailer:      m,
+		FrontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// SendVerification sends the email verification link to the user.
+func (m *AccountMailer) SendVerification(user entity.User, token string) error {
+	link := m.link("/verify-email", token)
+
+	return m.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nPlease confirm your email address by opening the link below:\r\n\r\n%s\r\n\r\nThe link expires in 24 hours. If you did not create an account, you can ignore this email.",
+			user.Name, link),
+	})
+}
+
+// SendPasswordReset sends the password reset link to the user.
+func (m *AccountMailer) SendPasswordReset(user entity.User, token string) error {
+	link := m.link("/reset-password", token)
+
+	return m.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nWe received a request to reset your password. Open the link below to choose a new one:\r\n\r\n%s\r\n\r\nThe link expires in 1 hour and can only be used once. If you did not request a reset, you can ignore this email.",
+			user.Name, link),
+	})
+}
+
+// SendMagicLink sends a passwordless sign-in link to the user.
+func (m *AccountMailer) SendMagicLink(user entity.User, token string) error {
+	link := m.link("/magic-link", token)
+
+	return m.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nOpen the link below to sign in:\r\n\r\n%s\r\n\r\nThe link expires in 15 minutes, can only be used once and only works in the browser it was requested from. If you did not ask to sign in, you can ignore this email.",
+			user.Name, link),
+	})
+}
+
+// SendNewSignIn warns the user about a sign-in from a device or network
+// they have not used before.
+func (m *AccountMailer) SendNewSignIn(user entity.User, event entity.LoginEvent) error {
+	location := geoip.Location{Country: event.Country, City: event.City}.String()
+	if location == "" {
+		location = "unknown"
+	}
+
+	return m.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "New sign-in to your account",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nYour account was just signed in to from a new device or location:\r\n\r\nTime: %s\r\nIP address: %s\r\nLocation: %s\r\nBrowser: %s\r\n\r\nIf this was you, you can ignore this email. If not, reset your password right away:\r\n\r\n%s",
+			user.Name, event.CreatedAt.UTC().Format(time.RFC1123), event.IP, location, event.UserAgent, m.FrontendURL+"/forgot-password"),
+	})
+}
+
+func (m *AccountMailer) link(path, token string) string {
+	return m.FrontendURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendVerification issues a verification token and mails it. Delivery
+// failures are logged rather than returned, the user can always ask for
+// the email to be resent. Callers run it in the background, so neither
+// the response nor its timing waits for the mail server.
+func sendVerification(accountMailer *AccountMailer, user entity.User) {
+	log := logger.GetLogger()
+
+	token, err := helper.GenerateEmailVerificationToken(user)
+	if err != nil {
+		log.WithError(err).Error("failed to generate verification token: " + user.Email)
+		return
+	}
+
+	if err := accountMailer.SendVerification(user, token); err != nil {
+		log.WithError(err).Error("failed to send verification email: " + user.Email)
+	}
+}
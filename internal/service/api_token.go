@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// ApiTokenPrefix starts every personal access token, so they are easy to
+// recognise in configs and by secret scanners.
+const ApiTokenPrefix = "gfs_"
+
+const (
+	defaultApiTokenTTL = 90 * 24 * time.Hour
+	// last_used_at is only written once per interval to keep busy tokens
+	// from updating their row on every request
+	apiTokenTouchInterval = time.Minute
+	// characters of the random part kept in the prefix
+	apiTokenPrefixLength = 8
+)
+
+var (
+	ErrApiTokenNotFound = errors.New("api token not found")
+	ErrInvalidApiToken  = errors.New("invalid api token")
+	ErrApiTokenScope    = errors.New("scope not held by the user")
+)
+
+// ApiTokenIdentity is the user behind a valid API token. Permissions are
+// the user's current permissions limited to the token scopes.
+type ApiTokenIdentity struct {
+	TokenId     uuid.UUID
+	User        entity.User
+	Permissions []string
+}
+
+type IApiTokenService interface {
+	Create(req request.ApiTokenCreateRequest) (response.ApiTokenResponse, error)
+	FindAllByUserId(userId uuid.UUID) ([]response.ApiTokenResponse, error)
+	FindById(userId, tokenId uuid.UUID) (response.ApiTokenResponse, error)
+	Delete(userId, tokenId uuid.UUID) error
+	Authenticate(token string) (ApiTokenIdentity, error)
+}
+
+type ApiTokenService struct {
+	IApiTokenRepository repository.IApiTokenRepository
+	IAuthRepository     repository.IAuthRepository
+	validate            *validator.Validate
+}
+
+func NewApiTokenService(repo repository.IApiTokenRepository, authRepo repository.IAuthRepository, validate *validator.Validate) IApiTokenService {
+	return &ApiTokenService{
+		IApiTokenRepository: repo,
+		IAuthRepository:     authRepo,
+		validate:            validate,
+	}
+}
+
+// Create implements IApiTokenService. Scopes must be permissions the user
+// holds. The token itself is only returned here, it is stored hashed.
+func (e *ApiTokenService) Create(req request.ApiTokenCreateRequest) (response.ApiTokenResponse, error) {
+	if err := e.validate.Struct(req); err != nil {
+		return response.ApiTokenResponse{}, err
+	}
+
+	user, err := e.IAuthRepository.FindById(req.UserId)
+	if err != nil {
+		return response.ApiTokenResponse{}, err
+	}
+
+	held := user.PermissionNames()
+	scopes := unique(req.Scopes)
+	for _, scope := range scopes {
+		if !contains(held, scope) {
+			return response.ApiTokenResponse{}, fmt.Errorf("%w: %s", ErrApiTokenScope, scope)
+		}
+	}
+
+	secret, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return response.ApiTokenResponse{}, err
+	}
+	token := ApiTokenPrefix + secret
+
+	ttl := defaultApiTokenTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	apiToken, err := e.IApiTokenRepository.Create(entity.ApiToken{
+		UserId:    user.Id,
+		Name:      req.Name,
+		Prefix:    token[:len(ApiTokenPrefix)+apiTokenPrefixLength],
+		TokenHash: helper.HashToken(token),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return response.ApiTokenResponse{}, err
+	}
+
+	res := toApiTokenResponse(apiToken)
+	res.Token = token
+	return res, nil
+}
+
+// FindAllByUserId implements IApiTokenService.
+func (e *ApiTokenService) FindAllByUserId(userId uuid.UUID) ([]response.ApiTokenResponse, error) {
+	tokens, err := e.IApiTokenRepository.FindAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	resps := []response.ApiTokenResponse{}
+	for _, value := range tokens {
+		resps = append(resps, toApiTokenResponse(value))
+	}
+	return resps, nil
+}
+
+// FindById implements IApiTokenService. Only tokens owned by userId are
+// found.
+func (e *ApiTokenService) FindById(userId, tokenId uuid.UUID) (response.ApiTokenResponse, error) {
+	apiToken, err := e.IApiTokenRepository.FindById(tokenId)
+	if err != nil || apiToken.UserId != userId {
+		return response.ApiTokenResponse{}, ErrApiTokenNotFound
+	}
+	return toApiTokenResponse(apiToken), nil
+}
+
+// Delete implements IApiTokenService. The token stops working immediately.
+func (e *ApiTokenService) Delete(userId, tokenId uuid.UUID) error {
+	apiToken, err := e.IApiTokenRepository.FindById(tokenId)
+	if err != nil || apiToken.UserId != userId {
+		return ErrApiTokenNotFound
+	}
+	return e.IApiTokenRepository.Delete(apiToken.Id)
+}
+
+// Authenticate implements IApiTokenService. Permissions are resolved on
+// every call, so a user losing a role also limits their tokens.
+func (e *ApiTokenService) Authenticate(token string) (ApiTokenIdentity, error) {
+	if !strings.HasPrefix(token, ApiTokenPrefix) {
+		return ApiTokenIdentity{}, ErrInvalidApiToken
+	}
+
+	apiToken, err := e.IApiTokenRepository.FindByHash(helper.HashToken(token))
+	if err != nil || apiToken.IsExpired() {
+		return ApiTokenIdentity{}, ErrInvalidApiToken
+	}
+
+	user, err := e.IAuthRepository.FindById(apiToken.UserId)
+	if err != nil {
+		return ApiTokenIdentity{}, ErrInvalidApiToken
+	}
+
+	permissions := []string{}
+	for _, permission := range user.PermissionNames() {
+		if contains(apiToken.ScopeList(), permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	now := time.Now()
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= apiTokenTouchInterval {
+		if err := e.IApiTokenRepository.Touch(apiToken.Id, now); err != nil {
+			logger.GetLogger().WithError(err).Error("failed to record api token use")
+		}
+	}
+
+	return ApiTokenIdentity{
+		TokenId:     apiToken.Id,
+		User:        user,
+		Permissions: permissions,
+	}, nil
+}
+
+func toApiTokenResponse(apiToken entity.ApiToken) response.ApiTokenResponse {
+	return response.ApiTokenResponse{
+		Id:         apiToken.Id,
+		Name:       apiToken.Name,
+		Prefix:     apiToken.Prefix,
+		Scopes:     apiToken.ScopeList(),
+		ExpiresAt:  apiToken.ExpiresAt,
+		LastUsedAt: apiToken.LastUsedAt,
+		CreatedAt:  apiToken.CreatedAt,
+	}
+}
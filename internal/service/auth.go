@@ -1,64 +1,353 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
 	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
 	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
 	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/password"
 	"github.com/go-playground/validator/v10"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
 )
 
+var (
+	ErrUsernameTaken = errors.New("username has already been taken")
+	ErrEmailTaken    = errors.New("email has already been taken")
+
+	ErrEmailNotVerified         = errors.New("email address has not been verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrInvalidResetToken        = errors.New("invalid or expired password reset token")
+)
+
+const passwordResetTTL = time.Hour
+
+// AuthConfig holds the behaviour switches of the authentication flow.
+type AuthConfig struct {
+	RequireVerifiedEmail bool
+}
+
 type IAuthService interface {
-	Register(req request.RegisterRequest) (response.RegisterResponse, error)
-	Login(req request.LoginRequest) (response.LoginResponse, error)
+	Register(ctx context.Context, req request.RegisterRequest) (response.RegisterResponse, error)
+	Login(ctx context.Context, req request.LoginRequest, client ClientInfo) (response.LoginResponse, error)
+	VerifyEmail(req request.VerifyEmailRequest) error
+	ResendVerification(req request.ResendVerificationRequest) error
+	ForgotPassword(req request.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req request.ResetPasswordRequest) error
+	FindById(userId uuid.UUID) (entity.User, error)
 }
 type AuthService struct {
-	IAuthRepository repository.IAuthRepository
-	validate        *validator.Validate
+	IAuthRepository          repository.IAuthRepository
+	IPasswordResetRepository repository.IPasswordResetRepository
+	ISessionService          ISessionService
+	ILoginThrottleService    ILoginThrottleService
+	ILoginEventService       ILoginEventService
+	AccountMailer            *AccountMailer
+	Hasher                   password.Hasher
+	IPasswordPolicyService   IPasswordPolicyService
+	config                   AuthConfig
+	validate                 *validator.Validate
 }
 
-func NewAuthService(repo repository.IAuthRepository, validate *validator.Validate) IAuthService {
+func NewAuthService(repo repository.IAuthRepository, resetRepo repository.IPasswordResetRepository, sessionServ ISessionService, throttleServ ILoginThrottleService, loginEvents ILoginEventService, accountMailer *AccountMailer, hasher password.Hasher, policyServ IPasswordPolicyService, config AuthConfig, validate *validator.Validate) IAuthService {
 	return &AuthService{
-		IAuthRepository: repo,
-		validate:        validate,
+		IAuthRepository:          repo,
+		IPasswordResetRepository: resetRepo,
+		ISessionService:          sessionServ,
+		ILoginThrottleService:    throttleServ,
+		ILoginEventService:       loginEvents,
+		AccountMailer:            accountMailer,
+		Hasher:                   hasher,
+		IPasswordPolicyService:   policyServ,
+		config:                   config,
+		validate:                 validate,
 	}
 }
 
 // Register implements IAuthService.
-func (e *AuthService) Register(req request.RegisterRequest) (response.RegisterResponse, error) {
-	panic("unimplemented")
+func (e *AuthService) Register(ctx context.Context, req request.RegisterRequest) (response.RegisterResponse, error) {
+	var res response.RegisterResponse
+
+	if err := e.validate.Struct(req); err != nil {
+		return res, err
+	}
+
+	username := strings.ToLower(req.Username)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	exists, err := e.IAuthRepository.ExistsByUsername(username)
+	if err != nil {
+		return res, err
+	}
+	if exists {
+		return res, ErrUsernameTaken
+	}
+
+	exists, err = e.IAuthRepository.ExistsByEmail(email)
+	if err != nil {
+		return res, err
+	}
+	if exists {
+		return res, ErrEmailTaken
+	}
+
+	user := entity.User{
+		Username: username,
+		Name:     req.Name,
+		Email:    email,
+	}
+
+	if err := e.IPasswordPolicyService.Check(ctx, user, req.Password); err != nil {
+		return res, err
+	}
+
+	user.Password, err = password.HashContext(ctx, e.Hasher, req.Password)
+	if err != nil {
+		return res, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err = e.IAuthRepository.Register(user)
+	switch {
+	case errors.Is(err, repository.ErrDuplicateUsername):
+		// another registration took the name since the check above
+		return res, ErrUsernameTaken
+	case errors.Is(err, repository.ErrDuplicateEmail):
+		return res, ErrEmailTaken
+	case err != nil:
+		return res, err
+	}
+
+	e.IPasswordPolicyService.Remember(user.Id, user.Password)
+
+	go sendVerification(e.AccountMailer, user)
+
+	return response.RegisterResponse{
+		Id:        user.Id,
+		Username:  user.Username,
+		Email:     user.Email,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}, nil
 }
 
-// Login implements IAuthService.
-func (e *AuthService) Login(req request.LoginRequest) (response.LoginResponse, error) {
+// Login implements IAuthService. Throttled attempts fail with a
+// *ThrottleError before the password is checked.
+func (e *AuthService) Login(ctx context.Context, req request.LoginRequest, client ClientInfo) (response.LoginResponse, error) {
 	var res response.LoginResponse
 
-	result, err := e.IAuthRepository.Login(req.Email)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	if err := e.ILoginThrottleService.Check(email, client.IP); err != nil {
+		return res, err
+	}
+
+	result, err := e.IAuthRepository.Login(email)
 	if err != nil {
+		e.ILoginThrottleService.RecordFailure(email, nil, client)
 		return res, err
 	}
 
-	err = ValidatePassword(req.Password, result.Password)
+	err = password.VerifyContext(ctx, e.Hasher, req.Password, result.Password)
+	// a busy hasher or a cancelled request never checked the password
+	if errors.Is(err, password.ErrPoolSaturated) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return res, err
+	}
 	if err != nil {
+		e.ILoginThrottleService.RecordFailure(email, &result.Id, client)
+		e.ILoginEventService.RecordFailure(result, entity.LoginMethodPassword, "invalid_password", client)
 		return res, errors.New("credentials does not matches our record")
 	}
 
-	token, err := helper.GenerateRefreshToken(result)
-	if err != nil {
-		return res, fmt.Errorf("failed to generate token: %w", err)
+	// the second factor of MFA accounts may still fail, MfaService.Verify
+	// resets the throttle
+	if !result.MfaEnabled {
+		e.ILoginThrottleService.RecordSuccess(email)
+	}
+	e.rehash(ctx, result, req.Password)
+
+	if e.config.RequireVerifiedEmail && result.EmailVerifiedAt == nil {
+		e.ILoginEventService.RecordFailure(result, entity.LoginMethodPassword, "email_not_verified", client)
+		return res, ErrEmailNotVerified
+	}
+
+	// MFA accounts have not signed in yet, MfaService.Verify records it
+	if !result.MfaEnabled {
+		e.ILoginEventService.RecordSuccess(result, entity.LoginMethodPassword, client)
 	}
 
 	return response.LoginResponse{
-		Token: token,
-		User:  result,
+		User: result,
 	}, nil
 }
 
-// ValidatePassword compares a plain password with a hashed password
-func ValidatePassword(password, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// VerifyEmail implements IAuthService.
+func (e *AuthService) VerifyEmail(req request.VerifyEmailRequest) error {
+	if err := e.validate.Struct(req); err != nil {
+		return err
+	}
+
+	claims, err := helper.ParseEmailVerificationToken(req.Token)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	idStr, _ := claims["id"].(string)
+	userId, err := uuid.Parse(idStr)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := e.IAuthRepository.FindById(userId)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	// the token is bound to the address it was sent to, so a token issued
+	// before an email change cannot verify the new address
+	if email, _ := claims["email"].(string); email != user.Email {
+		return ErrInvalidVerificationToken
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return e.IAuthRepository.MarkEmailVerified(user.Id, time.Now())
+}
+
+// ResendVerification implements IAuthService. It reports success for unknown
+// or already verified addresses so the endpoint cannot be used to probe
+// which emails are registered.
+func (e *AuthService) ResendVerification(req request.ResendVerificationRequest) error {
+	if err := e.validate.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := e.IAuthRepository.FindByEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	go sendVerification(e.AccountMailer, user)
+	return nil
+}
+
+// ForgotPassword implements IAuthService. Unknown addresses are accepted
+// silently and the email is delivered in the background, so neither the
+// response nor its timing tells whether the account exists.
+func (e *AuthService) ForgotPassword(req request.ForgotPasswordRequest) error {
+	if err := e.validate.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := e.IAuthRepository.FindByEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		return nil
+	}
+
+	token, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// only the most recent link stays valid
+	if err := e.IPasswordResetRepository.DeleteByUserId(user.Id); err != nil {
+		return err
+	}
+
+	_, err = e.IPasswordResetRepository.Create(entity.PasswordReset{
+		UserId:    user.Id,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := e.AccountMailer.SendPasswordReset(user, token); err != nil {
+			logger.GetLogger().WithError(err).Error("failed to send password reset email: " + user.Email)
+		}
+	}()
+
+	return nil
+}
+
+// ResetPassword implements IAuthService.
+func (e *AuthService) ResetPassword(ctx context.Context, req request.ResetPasswordRequest) error {
+	if err := e.validate.Struct(req); err != nil {
+		return err
+	}
+
+	reset, err := e.IPasswordResetRepository.FindByTokenHash(helper.HashToken(req.Token))
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := e.IAuthRepository.FindById(reset.UserId)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := e.IPasswordPolicyService.Check(ctx, user, req.Password); err != nil {
+		return err
+	}
+
+	// hash first, so a busy hasher does not use up the token
+	hashed, err := password.HashContext(ctx, e.Hasher, req.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	consumed, err := e.IPasswordResetRepository.Consume(reset.Id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidResetToken
+	}
+
+	if err := e.IAuthRepository.UpdatePassword(reset.UserId, hashed); err != nil {
+		return err
+	}
+
+	e.IPasswordPolicyService.Remember(reset.UserId, hashed)
+
+	// sign the user out everywhere, whoever triggered the reset may have
+	// been using a stolen session
+	return e.ISessionService.RevokeAll(reset.UserId)
+}
+
+// FindById implements IAuthService.
+func (e *AuthService) FindById(userId uuid.UUID) (entity.User, error) {
+	return e.IAuthRepository.FindById(userId)
+}
+
+// rehash upgrades the stored hash of a password that has just been
+// verified when it was made with an outdated algorithm or parameters.
+// Failures are only logged, the old hash keeps working.
+func (e *AuthService) rehash(ctx context.Context, user entity.User, plain string) {
+	if !e.Hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	log := logger.GetLogger()
+
+	hashed, err := password.HashContext(ctx, e.Hasher, plain)
+	if err != nil {
+		log.WithError(err).Error("failed to rehash password: " + user.Email)
+		return
+	}
+
+	if err := e.IAuthRepository.UpdatePassword(user.Id, hashed); err != nil {
+		log.WithError(err).Error("failed to store rehashed password: " + user.Email)
+	}
 }
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImpersonateSelf         = errors.New("you cannot impersonate yourself")
+	ErrImpersonationForbidden  = errors.New("users who can impersonate or hold permissions you lack cannot be impersonated")
+	ErrNotImpersonating        = errors.New("no impersonation is active")
+	ErrImpersonationNeedsLogin = errors.New("impersonation requires a signed in session")
+)
+
+type IImpersonationService interface {
+	Start(actorId, sessionId, userId uuid.UUID, client ClientInfo) (response.ImpersonationResponse, error)
+	End(accessToken string, client ClientInfo) (string, error)
+}
+
+type ImpersonationService struct {
+	IAuthRepository       repository.IAuthRepository
+	ISessionRepository    repository.ISessionRepository
+	ISecurityEventService ISecurityEventService
+	TokenStore            tokenstore.TokenStore
+}
+
+func NewImpersonationService(repo repository.IAuthRepository, sessionRepo repository.ISessionRepository, events ISecurityEventService, tokenStore tokenstore.TokenStore) IImpersonationService {
+	return &ImpersonationService{
+		IAuthRepository:       repo,
+		ISessionRepository:    sessionRepo,
+		ISecurityEventService: events,
+		TokenStore:            tokenStore,
+	}
+}
+
+// Start implements IImpersonationService. It issues an access token for the
+// user on the actor's session; there is no refresh token, the
+// impersonation lasts at most helper.ImpersonationTTL. Only users whose
+// permissions the actor holds as well can be impersonated, and not those
+// holding the impersonate permission themselves, so it cannot be used to
+// borrow anyone's rights.
+func (e *ImpersonationService) Start(actorId, sessionId, userId uuid.UUID, client ClientInfo) (response.ImpersonationResponse, error) {
+	var res response.ImpersonationResponse
+
+	if sessionId == uuid.Nil {
+		return res, ErrImpersonationNeedsLogin
+	}
+	if actorId == userId {
+		return res, ErrImpersonateSelf
+	}
+
+	actor, err := e.IAuthRepository.FindById(actorId)
+	if err != nil {
+		return res, err
+	}
+
+	user, err := e.IAuthRepository.FindById(userId)
+	if err != nil {
+		return res, err
+	}
+
+	actorPermissions := map[string]bool{}
+	for _, permission := range actor.PermissionNames() {
+		actorPermissions[permission] = true
+	}
+	for _, permission := range user.PermissionNames() {
+		if permission == entity.PermissionUsersImpersonate || !actorPermissions[permission] {
+			return res, ErrImpersonationForbidden
+		}
+	}
+
+	token, err := helper.GenerateImpersonationToken(user, actor, sessionId.String())
+	if err != nil {
+		return res, err
+	}
+
+	e.ISecurityEventService.Record(entity.SecurityEventImpersonationStart, &user.Id, client,
+		fmt.Sprintf("%s impersonated by %s (%s)", user.Email, actor.Username, actor.Id))
+
+	return response.ImpersonationResponse{
+		AccessToken: token,
+		ExpiresAt:   time.Now().Add(helper.ImpersonationTTL),
+		UserId:      user.Id,
+		ActorId:     actor.Id,
+	}, nil
+}
+
+// End implements IImpersonationService. The impersonation token is revoked
+// and a regular access token of the actor's session is returned, so the
+// admin is back as themselves without signing in again. It fails with
+// ErrSessionRevoked when the admin has signed out since, the token would
+// otherwise bring the revoked session back.
+func (e *ImpersonationService) End(accessToken string, client ClientInfo) (string, error) {
+	claims, err := helper.ParseToken(accessToken, false)
+	if err != nil || claims.Actor == nil {
+		return "", ErrNotImpersonating
+	}
+
+	actorId, err := uuid.Parse(claims.Actor.Subject)
+	if err != nil {
+		return "", ErrNotImpersonating
+	}
+	sessionId, err := uuid.Parse(claims.SessionId)
+	if err != nil {
+		return "", ErrNotImpersonating
+	}
+
+	session, err := e.ISessionRepository.FindById(sessionId)
+	if err != nil || session.UserId != actorId || !session.IsActive() {
+		return "", ErrSessionRevoked
+	}
+
+	revoked, err := tokenstore.IsTokenRevoked(e.TokenStore, claims.ID, claims.Actor.Subject, claims.SessionId, claims.IssuedAt.Time)
+	if err != nil {
+		return "", err
+	}
+	if revoked {
+		return "", ErrSessionRevoked
+	}
+
+	if err := e.TokenStore.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+		return "", err
+	}
+
+	actor, err := e.IAuthRepository.FindById(actorId)
+	if err != nil {
+		return "", err
+	}
+
+	userId, _ := uuid.Parse(claims.Id)
+	e.ISecurityEventService.Record(entity.SecurityEventImpersonationEnd, &userId, client,
+		fmt.Sprintf("impersonation of %s by %s (%s) ended", claims.Username, actor.Username, actor.Id))
+
+	return helper.GenerateAccessToken(actor, claims.SessionId, "", "")
+}
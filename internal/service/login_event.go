@@ -0,0 +1,113 @@
+package service
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/google/uuid"
+)
+
+type ILoginEventService interface {
+	RecordSuccess(user entity.User, method string, client ClientInfo)
+	RecordFailure(user entity.User, method, reason string, client ClientInfo)
+	FindAllByUserId(userId uuid.UUID, page, pageSize int) ([]entity.LoginEvent, int, error)
+}
+
+type LoginEventService struct {
+	ILoginEventRepository repository.ILoginEventRepository
+	AccountMailer         *AccountMailer
+}
+
+func NewLoginEventService(repo repository.ILoginEventRepository, accountMailer *AccountMailer) ILoginEventService {
+	return &LoginEventService{ILoginEventRepository: repo, AccountMailer: accountMailer}
+}
+
+// RecordSuccess implements ILoginEventService. When the sign-in comes from
+// a device or an IP range the user never signed in from before, a new
+// sign-in email is sent. The first sign-in of an account sends none.
+// Failures are only logged, recording must never fail the login.
+func (e *LoginEventService) RecordSuccess(user entity.User, method string, client ClientInfo) {
+	event := newLoginEvent(user, method, client)
+	event.Success = true
+
+	isNew := e.isNewSignIn(event)
+
+	event, err := e.ILoginEventRepository.Create(event)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("failed to store login event: " + user.Email)
+		return
+	}
+
+	if !isNew || e.AccountMailer == nil {
+		return
+	}
+
+	go func() {
+		if err := e.AccountMailer.SendNewSignIn(user, event); err != nil {
+			logger.GetLogger().WithError(err).Error("failed to send new sign-in email: " + user.Email)
+		}
+	}()
+}
+
+// RecordFailure implements ILoginEventService.
+func (e *LoginEventService) RecordFailure(user entity.User, method, reason string, client ClientInfo) {
+	event := newLoginEvent(user, method, client)
+	event.FailureReason = reason
+
+	if _, err := e.ILoginEventRepository.Create(event); err != nil {
+		logger.GetLogger().WithError(err).Error("failed to store login event: " + user.Email)
+	}
+}
+
+// FindAllByUserId implements ILoginEventService.
+func (e *LoginEventService) FindAllByUserId(userId uuid.UUID, page, pageSize int) ([]entity.LoginEvent, int, error) {
+	return e.ILoginEventRepository.FindAllByUserId(userId, page, pageSize)
+}
+
+// isNewSignIn reports whether the user has signed in before, but never
+// from this device or this IP range. Clients without a device cookie are
+// judged by the range alone.
+func (e *LoginEventService) isNewSignIn(event entity.LoginEvent) bool {
+	log := logger.GetLogger()
+
+	total, err := e.ILoginEventRepository.CountSuccesses(event.UserId, "", "")
+	if err != nil {
+		log.WithError(err).Error("failed to read login history")
+		return false
+	}
+	if total == 0 {
+		return false
+	}
+
+	if event.Device != "" {
+		count, err := e.ILoginEventRepository.CountSuccesses(event.UserId, event.Device, "")
+		if err != nil {
+			log.WithError(err).Error("failed to read login history")
+			return false
+		}
+		if count == 0 {
+			return true
+		}
+	}
+
+	count, err := e.ILoginEventRepository.CountSuccesses(event.UserId, "", event.Network)
+	if err != nil {
+		log.WithError(err).Error("failed to read login history")
+		return false
+	}
+	return count == 0
+}
+
+func newLoginEvent(user entity.User, method string, client ClientInfo) entity.LoginEvent {
+	return entity.LoginEvent{
+		UserId:    user.Id,
+		Method:    method,
+		IP:        client.IP,
+		Network:   helper.IPNetwork(client.IP),
+		UserAgent: client.UserAgent,
+		Device:    client.Device,
+		Country:   client.Country,
+		City:      client.City,
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAccountLocked   = errors.New("account is temporarily locked")
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
+)
+
+// ThrottleError refuses a login attempt before the password is checked.
+// It wraps ErrAccountLocked or ErrTooManyAttempts.
+type ThrottleError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ThrottleError) Unwrap() error {
+	return e.Err
+}
+
+// ThrottleConfig tunes the login throttling. Zero values use the defaults.
+type ThrottleConfig struct {
+	// FreeAttempts is the number of failures allowed without any delay.
+	FreeAttempts int
+	// BaseDelay is the delay after the first throttled failure, doubled by
+	// every further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxFailures is the number of failures on one account that locks it
+	// for LockoutDuration.
+	MaxFailures     int
+	LockoutDuration time.Duration
+	// Window is how long failures are remembered. A counter without a
+	// failure for that long starts over and is eventually pruned.
+	Window time.Duration
+	// PruneInterval is how often stale counters are deleted, negative
+	// disables pruning.
+	PruneInterval time.Duration
+	// MaxChallengeFailures is the number of wrong codes one MFA challenge
+	// accepts before it is invalidated.
+	MaxChallengeFailures int
+}
+
+type ILoginThrottleService interface {
+	Check(email, ip string) error
+	RecordFailure(email string, userId *uuid.UUID, client ClientInfo)
+	RecordSuccess(email string)
+	RecordChallengeFailure(challengeId string) bool
+	Unlock(userId uuid.UUID, actorId uuid.UUID, client ClientInfo) error
+}
+
+type LoginThrottleService struct {
+	ILoginThrottleRepository repository.ILoginThrottleRepository
+	IAuthRepository          repository.IAuthRepository
+	ISecurityEventService    ISecurityEventService
+	config                   ThrottleConfig
+}
+
+func NewLoginThrottleService(repo repository.ILoginThrottleRepository, authRepo repository.IAuthRepository, events ISecurityEventService, config ThrottleConfig) ILoginThrottleService {
+	if config.FreeAttempts == 0 {
+		config.FreeAttempts = 3
+	}
+	if config.BaseDelay == 0 {
+		config.BaseDelay = time.Second
+	}
+	if config.MaxDelay == 0 {
+		config.MaxDelay = 5 * time.Minute
+	}
+	if config.MaxFailures == 0 {
+		config.MaxFailures = 10
+	}
+	if config.LockoutDuration == 0 {
+		config.LockoutDuration = 15 * time.Minute
+	}
+	if config.MaxChallengeFailures == 0 {
+		config.MaxChallengeFailures = 5
+	}
+	if config.Window == 0 {
+		config.Window = time.Hour
+	}
+	if config.PruneInterval == 0 {
+		config.PruneInterval = 10 * time.Minute
+	}
+
+	e := &LoginThrottleService{
+		ILoginThrottleRepository: repo,
+		IAuthRepository:          authRepo,
+		ISecurityEventService:    events,
+		config:                   config,
+	}
+	if config.PruneInterval > 0 {
+		go e.prune(config.PruneInterval)
+	}
+	return e
+}
+
+// Check implements ILoginThrottleService. Failures are tracked per account
+// and per client address, so guessing many passwords for one account and
+// one password for many accounts are both slowed down.
+func (e *LoginThrottleService) Check(email, ip string) error {
+	now := time.Now()
+
+	account, err := e.ILoginThrottleRepository.Find(accountThrottleKey(email))
+	if err != nil {
+		return err
+	}
+
+	if account.LockedUntil != nil && now.Before(*account.LockedUntil) {
+		return &ThrottleError{Err: ErrAccountLocked, RetryAfter: account.LockedUntil.Sub(now)}
+	}
+
+	client, err := e.ILoginThrottleRepository.Find(ipThrottleKey(ip))
+	if err != nil {
+		return err
+	}
+
+	for _, throttle := range []entity.LoginThrottle{account, client} {
+		// failures older than the window are forgotten
+		if throttle.LastFailureAt.Before(now.Add(-e.config.Window)) {
+			continue
+		}
+		if wait := throttle.LastFailureAt.Add(e.delay(throttle.Failures)).Sub(now); wait > 0 {
+			return &ThrottleError{Err: ErrTooManyAttempts, RetryAfter: wait}
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure implements ILoginThrottleService. userId is nil when the
+// email does not belong to an account, the attempt is counted anyway.
+func (e *LoginThrottleService) RecordFailure(email string, userId *uuid.UUID, client ClientInfo) {
+	log := logger.GetLogger()
+	now := time.Now()
+
+	since := now.Add(-e.config.Window)
+
+	if _, err := e.ILoginThrottleRepository.RecordFailure(ipThrottleKey(client.IP), now, since); err != nil {
+		log.WithError(err).Error("failed to record login failure: " + client.IP)
+	}
+
+	account, err := e.ILoginThrottleRepository.RecordFailure(accountThrottleKey(email), now, since)
+	if err != nil {
+		log.WithError(err).Error("failed to record login failure: " + email)
+		return
+	}
+
+	if account.Failures < e.config.MaxFailures {
+		return
+	}
+
+	if err := e.ILoginThrottleRepository.Lock(account.Key, now.Add(e.config.LockoutDuration)); err != nil {
+		log.WithError(err).Error("failed to lock account: " + email)
+		return
+	}
+
+	e.ISecurityEventService.Record(entity.SecurityEventAccountLocked, userId, client,
+		fmt.Sprintf("%s locked for %s after %d failed login attempts", email, e.config.LockoutDuration, account.Failures))
+}
+
+// RecordSuccess implements ILoginThrottleService. Only the account counter
+// is reset, the client address keeps its failures.
+func (e *LoginThrottleService) RecordSuccess(email string) {
+	if err := e.ILoginThrottleRepository.Reset(accountThrottleKey(email)); err != nil {
+		logger.GetLogger().WithError(err).Error("failed to reset login throttle: " + email)
+	}
+}
+
+// RecordChallengeFailure implements ILoginThrottleService. It counts a
+// wrong second factor for the MFA challenge and reports whether the
+// challenge is used up. Failing to count also uses it up.
+func (e *LoginThrottleService) RecordChallengeFailure(challengeId string) bool {
+	now := time.Now()
+	challenge, err := e.ILoginThrottleRepository.RecordFailure(challengeThrottleKey(challengeId), now, now.Add(-e.config.Window))
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("failed to record mfa failure: " + challengeId)
+		return true
+	}
+
+	return challenge.Failures >= e.config.MaxChallengeFailures
+}
+
+// Unlock implements ILoginThrottleService. actorId is the administrator
+// lifting the lock.
+func (e *LoginThrottleService) Unlock(userId uuid.UUID, actorId uuid.UUID, client ClientInfo) error {
+	user, err := e.IAuthRepository.FindById(userId)
+	if err != nil {
+		return err
+	}
+
+	if err := e.ILoginThrottleRepository.Reset(accountThrottleKey(user.Email)); err != nil {
+		return err
+	}
+
+	e.ISecurityEventService.Record(entity.SecurityEventAccountUnlocked, &user.Id, client, fmt.Sprintf("%s unlocked by %s", user.Email, actorId))
+	return nil
+}
+
+// prune periodically deletes the counters that have been quiet for longer
+// than the window.
+func (e *LoginThrottleService) prune(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		if _, err := e.ILoginThrottleRepository.DeleteStale(now.Add(-e.config.Window), now); err != nil {
+			logger.GetLogger().WithError(err).Error("failed to prune login throttles")
+		}
+	}
+}
+
+// delay returns how long to wait after the last failure before the next
+// attempt is accepted.
+func (e *LoginThrottleService) delay(failures int) time.Duration {
+	exponent := failures - e.config.FreeAttempts
+	if exponent < 0 {
+		return 0
+	}
+	if exponent > 20 {
+		return e.config.MaxDelay
+	}
+
+	delay := e.config.BaseDelay << exponent
+	if delay > e.config.MaxDelay {
+		return e.config.MaxDelay
+	}
+	return delay
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + email
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+func challengeThrottleKey(challengeId string) string {
+	return "challenge:" + challengeId
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidMagicLink = errors.New("invalid or expired sign-in link")
+
+type IMagicLinkService interface {
+	Request(req request.MagicLinkRequest, fingerprint string) error
+	Consume(req request.MagicLinkConsumeRequest, fingerprint string, client ClientInfo) (entity.User, error)
+}
+
+type MagicLinkService struct {
+	IAuthRepository       repository.IAuthRepository
+	ILoginThrottleService ILoginThrottleService
+	ILoginEventService    ILoginEventService
+	TokenStore            tokenstore.TokenStore
+	AccountMailer         *AccountMailer
+	validate              *validator.Validate
+}
+
+func NewMagicLinkService(repo repository.IAuthRepository, throttleServ ILoginThrottleService, loginEvents ILoginEventService, tokenStore tokenstore.TokenStore, accountMailer *AccountMailer, validate *validator.Validate) IMagicLinkService {
+	return &MagicLinkService{
+		IAuthRepository:       repo,
+		ILoginThrottleService: throttleServ,
+		ILoginEventService:    loginEvents,
+		TokenStore:            tokenStore,
+		AccountMailer:         accountMailer,
+		validate:              validate,
+	}
+}
+
+// Request implements IMagicLinkService. Like ForgotPassword, unknown
+// addresses are accepted silently and the email is delivered in the
+// background.
+func (e *MagicLinkService) Request(req request.MagicLinkRequest, fingerprint string) error {
+	if err := e.validate.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := e.IAuthRepository.FindByEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		return nil
+	}
+
+	token, err := helper.GenerateMagicLinkToken(user, fingerprint)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := e.AccountMailer.SendMagicLink(user, token); err != nil {
+			logger.GetLogger().WithError(err).Error("failed to send magic link email: " + user.Email)
+		}
+	}()
+
+	return nil
+}
+
+// Consume implements IMagicLinkService. A link is accepted once, and only
+// from the device it was requested from. Opening it proves ownership of
+// the address, so an unverified email becomes verified.
+func (e *MagicLinkService) Consume(req request.MagicLinkConsumeRequest, fingerprint string, client ClientInfo) (entity.User, error) {
+	var user entity.User
+
+	if err := e.validate.Struct(req); err != nil {
+		return user, err
+	}
+
+	claims, err := helper.ParseMagicLinkToken(req.Token)
+	if err != nil {
+		return user, ErrInvalidMagicLink
+	}
+
+	idStr, _ := claims["id"].(string)
+	jti, _ := claims["jti"].(string)
+	fp, _ := claims["fp"].(string)
+	userId, err := uuid.Parse(idStr)
+	if err != nil || jti == "" || fingerprint == "" || subtle.ConstantTimeCompare([]byte(fp), []byte(fingerprint)) != 1 {
+		return user, ErrInvalidMagicLink
+	}
+
+	user, err = e.IAuthRepository.FindById(userId)
+	if err != nil {
+		return user, ErrInvalidMagicLink
+	}
+
+	// opening the link verifies the address, so a link sent before an
+	// email change cannot verify the new one
+	if email, _ := claims["email"].(string); email != user.Email {
+		return user, ErrInvalidMagicLink
+	}
+
+	// a locked account stays locked, whichever way the user signs in
+	if err := e.ILoginThrottleService.Check(user.Email, client.IP); err != nil {
+		return user, err
+	}
+
+	exp, _ := claims["exp"].(float64)
+	consumed, err := e.TokenStore.Consume("magic:"+jti, time.Unix(int64(exp), 0))
+	if err != nil {
+		return user, err
+	}
+	if !consumed {
+		return user, ErrInvalidMagicLink
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := e.IAuthRepository.MarkEmailVerified(user.Id, now); err != nil {
+			return user, err
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	// MFA accounts have not signed in yet, MfaService.Verify records it
+	if !user.MfaEnabled {
+		e.ILoginThrottleService.RecordSuccess(user.Email)
+		e.ILoginEventService.RecordSuccess(user, entity.LoginMethodMagicLink, client)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/password"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMfaAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMfaNotEnrolled      = errors.New("two-factor authentication has not been enrolled")
+	ErrMfaNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMfaCode      = errors.New("invalid authentication code")
+	ErrInvalidMfaChallenge = errors.New("invalid or expired mfa challenge")
+)
+
+const recoveryCodeCount = 10
+
+type IMfaService interface {
+	Enroll(userId uuid.UUID) (response.MfaEnrollResponse, error)
+	Confirm(userId uuid.UUID, req request.MfaConfirmRequest) (response.MfaRecoveryCodesResponse, error)
+	Disable(ctx context.Context, userId uuid.UUID, req request.MfaDisableRequest) error
+	Challenge(user entity.User) (response.MfaChallengeResponse, error)
+	Verify(req request.MfaVerifyRequest, client ClientInfo) (entity.User, error)
+}
+
+type MfaService struct {
+	IAuthRepository       repository.IAuthRepository
+	IMfaRepository        repository.IMfaRepository
+	ILoginThrottleService ILoginThrottleService
+	ILoginEventService    ILoginEventService
+	TokenStore            tokenstore.TokenStore
+	Hasher                password.Hasher
+	issuer                string
+	validate              *validator.Validate
+}
+
+func NewMfaService(authRepo repository.IAuthRepository, repo repository.IMfaRepository, throttleServ ILoginThrottleService, loginEvents ILoginEventService, tokenStore tokenstore.TokenStore, hasher password.Hasher, issuer string, validate *validator.Validate) IMfaService {
+	if issuer == "" {
+		issuer = "Go Fiber Service"
+	}
+
+	return &MfaService{
+		IAuthRepository:       authRepo,
+		IMfaRepository:        repo,
+		ILoginThrottleService: throttleServ,
+		ILoginEventService:    loginEvents,
+		TokenStore:            tokenStore,
+		Hasher:                hasher,
+		issuer:                issuer,
+		validate:              validate,
+	}
+}
+
+// Enroll implements IMfaService. It generates a new secret that becomes
+// active once Confirm receives a valid code for it. Enrolling again before
+// confirming replaces the pending secret.
+func (e *MfaService) Enroll(userId uuid.UUID) (response.MfaEnrollResponse, error) {
+	user, err := e.IAuthRepository.FindById(userId)
+	if err != nil {
+		return response.MfaEnrollResponse{}, err
+	}
+
+	if user.MfaEnabled {
+		return response.MfaEnrollResponse{}, ErrMfaAlreadyEnabled
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return response.MfaEnrollResponse{}, err
+	}
+
+	if err := e.IMfaRepository.SaveSecret(user.Id, secret); err != nil {
+		return response.MfaEnrollResponse{}, err
+	}
+
+	return response.MfaEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: helper.TOTPURI(e.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm implements IMfaService. It enables MFA and returns the recovery
+// codes, which are never shown again.
+func (e *MfaService) Confirm(userId uuid.UUID, req request.MfaConfirmRequest) (response.MfaRecoveryCodesResponse, error) {
+	var res response.MfaRecoveryCodesResponse
+
+	if err := e.validate.Struct(req); err != nil {
+		return res, err
+	}
+
+	user, err := e.IAuthRepository.FindById(userId)
+	if err != nil {
+		return res, err
+	}
+
+	if user.MfaEnabled {
+		return res, ErrMfaAlreadyEnabled
+	}
+	if user.MfaSecret == "" {
+		return res, ErrMfaNotEnrolled
+	}
+
+	ok, err := e.verifyTOTP(user, req.Code)
+	if err != nil {
+		return res, err
+	}
+	if !ok {
+		return res, ErrInvalidMfaCode
+	}
+
+	codes := []string{}
+	records := []entity.MfaRecoveryCode{}
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return res, err
+		}
+		codes = append(codes, code)
+		records = append(records, entity.MfaRecoveryCode{
+			UserId:   user.Id,
+			CodeHash: helper.HashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := e.IMfaRepository.Enable(user.Id, records); err != nil {
+		return res, err
+	}
+
+	return response.MfaRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable implements IMfaService. Both the password and a current code or
+// recovery code are required.
+func (e *MfaService) Disable(ctx context.Context, userId uuid.UUID, req request.MfaDisableRequest) error {
+	if err := e.validate.Struct(req); err != nil {
+		return err
+	}
+
+	user, err := e.IAuthRepository.FindById(userId)
+	if err != nil {
+		return err
+	}
+
+	if !user.MfaEnabled {
+		return ErrMfaNotEnabled
+	}
+
+	if err := password.VerifyContext(ctx, e.Hasher, req.Password, user.Password); err != nil {
+		if errors.Is(err, password.ErrPoolSaturated) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		return ErrInvalidMfaCode
+	}
+
+	ok, err := e.verifyCode(user, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMfaCode
+	}
+
+	return e.IMfaRepository.Disable(user.Id)
+}
+
+// Challenge implements IMfaService.
+func (e *MfaService) Challenge(user entity.User) (response.MfaChallengeResponse, error) {
+	token, err := helper.GenerateMfaChallengeToken(user)
+	if err != nil {
+		return response.MfaChallengeResponse{}, err
+	}
+
+	return response.MfaChallengeResponse{
+		ChallengeToken: token,
+		ExpiresAt:      time.Now().Add(helper.MfaChallengeTTL),
+	}, nil
+}
+
+// Verify implements IMfaService. It completes a login started with a
+// password by checking the second factor. A challenge can be completed
+// only once and is invalidated after a few wrong codes; wrong codes also
+// count towards the login throttle of the account and the address. The
+// sign-in is recorded in the login history only here, not by the first
+// factor.
+func (e *MfaService) Verify(req request.MfaVerifyRequest, client ClientInfo) (entity.User, error) {
+	var user entity.User
+
+	if err := e.validate.Struct(req); err != nil {
+		return user, err
+	}
+
+	claims, err := helper.ParseMfaChallengeToken(req.ChallengeToken)
+	if err != nil {
+		return user, ErrInvalidMfaChallenge
+	}
+
+	idStr, _ := claims["id"].(string)
+	jti, _ := claims["jti"].(string)
+	userId, err := uuid.Parse(idStr)
+	if err != nil || jti == "" {
+		return user, ErrInvalidMfaChallenge
+	}
+
+	user, err = e.IAuthRepository.FindById(userId)
+	if err != nil || !user.MfaEnabled {
+		return user, ErrInvalidMfaChallenge
+	}
+
+	// a used challenge must not burn a recovery code
+	challengeKey := "mfa:" + jti
+	exp, _ := claims["exp"].(float64)
+	expiresAt := time.Unix(int64(exp), 0)
+	used, err := e.TokenStore.IsRevoked(challengeKey)
+	if err != nil {
+		return user, err
+	}
+	if used {
+		return user, ErrInvalidMfaChallenge
+	}
+
+	if err := e.ILoginThrottleService.Check(user.Email, client.IP); err != nil {
+		return user, err
+	}
+
+	ok, err := e.verifyCode(user, req.Code)
+	if err != nil {
+		return user, err
+	}
+	if !ok {
+		e.ILoginThrottleService.RecordFailure(user.Email, &user.Id, client)
+		e.ILoginEventService.RecordFailure(user, entity.LoginMethodMfa, "invalid_code", client)
+		if e.ILoginThrottleService.RecordChallengeFailure(jti) {
+			if _, err := e.TokenStore.Consume(challengeKey, expiresAt); err != nil {
+				return user, err
+			}
+		}
+		return user, ErrInvalidMfaCode
+	}
+
+	consumed, err := e.TokenStore.Consume(challengeKey, expiresAt)
+	if err != nil {
+		return user, err
+	}
+	if !consumed {
+		return user, ErrInvalidMfaChallenge
+	}
+
+	e.ILoginThrottleService.RecordSuccess(user.Email)
+	e.ILoginEventService.RecordSuccess(user, entity.LoginMethodMfa, client)
+
+	return user, nil
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code.
+func (e *MfaService) verifyCode(user entity.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == 6 {
+		return e.verifyTOTP(user, code)
+	}
+
+	return e.IMfaRepository.ConsumeRecoveryCode(user.Id, helper.HashToken(normalizeRecoveryCode(code)), time.Now())
+}
+
+// verifyTOTP checks the code and marks its time step as used, so the same
+// code cannot be replayed within its validity window.
+func (e *MfaService) verifyTOTP(user entity.User, code string) (bool, error) {
+	step, ok := helper.ValidateTOTP(user.MfaSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return e.IMfaRepository.UseStep(user.Id, step)
+}
+
+// generateRecoveryCode returns a code such as "k3x9q-7mfpd".
+func generateRecoveryCode() (string, error) {
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(secret[:10])
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,532 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const authorizationCodeTTL = 5 * time.Minute
+
+var (
+	ErrOAuthClientNotFound = errors.New("oauth client not found")
+	ErrInvalidOAuthClient  = errors.New("invalid oauth client")
+)
+
+// OAuthError is an error of the OAuth endpoints. Code is one of the error
+// codes of RFC 6749, such as invalid_request or invalid_grant.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// OAuthConfig holds the provider settings.
+type OAuthConfig struct {
+	// Issuer is the public base URL of this service, used as the iss claim
+	// and to build the discovery document.
+	Issuer string
+	// LoginURL is the frontend page users are sent to when they authorize
+	// a client without being signed in.
+	LoginURL string
+}
+
+type IOAuthService interface {
+	CreateClient(req request.OAuthClientCreateRequest) (response.OAuthClientResponse, error)
+	FindAllClients() ([]response.OAuthClientResponse, error)
+	DeleteClient(reqId uuid.UUID) error
+	ValidateAuthorization(req request.AuthorizeRequest) (entity.OAuthClient, error)
+	Authorize(req request.AuthorizeRequest, client entity.OAuthClient, userId, sessionId uuid.UUID) (string, error)
+	Token(req request.TokenRequest, client ClientInfo) (response.OAuthTokenResponse, error)
+	UserInfo(userId uuid.UUID, scope string) (response.UserInfoResponse, error)
+	Introspect(req request.TokenIntrospectionRequest) (response.TokenIntrospectionResponse, error)
+	Revoke(req request.TokenRevocationRequest) error
+	Discovery() response.OpenIDConfiguration
+	LoginURL(returnTo string) string
+}
+
+type OAuthService struct {
+	IOAuthRepository   repository.IOAuthRepository
+	IAuthRepository    repository.IAuthRepository
+	ISessionRepository repository.ISessionRepository
+	ITokenService      ITokenService
+	config             OAuthConfig
+	validate           *validator.Validate
+}
+
+func NewOAuthService(repo repository.IOAuthRepository, authRepo repository.IAuthRepository, sessionRepo repository.ISessionRepository, tokenServ ITokenService, config OAuthConfig, validate *validator.Validate) IOAuthService {
+	// the listen address of main.go, for local development
+	if config.Issuer == "" {
+		config.Issuer = "http://127.0.0.1:3000"
+	}
+	config.Issuer = strings.TrimRight(config.Issuer, "/")
+
+	return &OAuthService{
+		IOAuthRepository:   repo,
+		IAuthRepository:    authRepo,
+		ISessionRepository: sessionRepo,
+		ITokenService:      tokenServ,
+		config:             config,
+		validate:           validate,
+	}
+}
+
+// CreateClient implements IOAuthService. The secret of confidential clients
+// is only returned here, it is stored hashed.
+func (e *OAuthService) CreateClient(req request.OAuthClientCreateRequest) (response.OAuthClientResponse, error) {
+	if err := e.validate.Struct(req); err != nil {
+		return response.OAuthClientResponse{}, err
+	}
+
+	client := entity.OAuthClient{
+		Name:         req.Name,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		Scopes:       strings.Join(unique(req.Scopes), " "),
+		GrantTypes:   strings.Join(unique(req.GrantTypes), " "),
+		Confidential: req.Confidential,
+	}
+	if client.Scopes == "" {
+		client.Scopes = "openid profile email"
+	}
+
+	if client.AllowsGrant(entity.GrantAuthorizationCode) && client.RedirectURIs == "" {
+		return response.OAuthClientResponse{}, errors.New("authorization_code clients need at least one redirect uri")
+	}
+	if client.AllowsGrant(entity.GrantClientCredentials) && !client.Confidential {
+		return response.OAuthClientResponse{}, errors.New("client_credentials is only available to confidential clients")
+	}
+
+	clientId, err := helper.GenerateRandomToken(16)
+	if err != nil {
+		return response.OAuthClientResponse{}, err
+	}
+	client.ClientId = clientId
+
+	var secret string
+	if client.Confidential {
+		secret, err = helper.GenerateRandomToken(32)
+		if err != nil {
+			return response.OAuthClientResponse{}, err
+		}
+		client.SecretHash = helper.HashToken(secret)
+	}
+
+	client, err = e.IOAuthRepository.CreateClient(client)
+	if err != nil {
+		return response.OAuthClientResponse{}, err
+	}
+
+	resp := toOAuthClientResponse(client)
+	resp.ClientSecret = secret
+	return resp, nil
+}
+
+// FindAllClients implements IOAuthService.
+func (e *OAuthService) FindAllClients() ([]response.OAuthClientResponse, error) {
+	clients, err := e.IOAuthRepository.FindAllClients()
+	if err != nil {
+		return nil, err
+	}
+
+	resps := []response.OAuthClientResponse{}
+	for _, value := range clients {
+		resps = append(resps, toOAuthClientResponse(value))
+	}
+	return resps, nil
+}
+
+// DeleteClient implements IOAuthService.
+func (e *OAuthService) DeleteClient(reqId uuid.UUID) error {
+	if err := e.IOAuthRepository.DeleteClient(reqId); err != nil {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+// ValidateAuthorization implements IOAuthService. It checks the client and
+// the redirect URI. Until both are known to be valid, errors must be shown
+// to the user instead of being sent to the redirect URI.
+func (e *OAuthService) ValidateAuthorization(req request.AuthorizeRequest) (entity.OAuthClient, error) {
+	client, err := e.IOAuthRepository.FindClientByClientId(req.ClientId)
+	if err != nil {
+		return client, oauthError("invalid_request", "unknown client_id")
+	}
+
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		return client, oauthError("invalid_request", "redirect_uri is not registered for this client")
+	}
+
+	return client, nil
+}
+
+// Authorize implements IOAuthService. It issues an authorization code for
+// the signed-in user and returns the redirect URI carrying it. PKCE with
+// S256 is required for every client.
+func (e *OAuthService) Authorize(req request.AuthorizeRequest, client entity.OAuthClient, userId, sessionId uuid.UUID) (string, error) {
+	if req.ResponseType != "code" {
+		return "", oauthError("unsupported_response_type", "only the code response type is supported")
+	}
+	if !client.AllowsGrant(entity.GrantAuthorizationCode) {
+		return "", oauthError("unauthorized_client", "client may not use the authorization code grant")
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return "", oauthError("invalid_request", "code_challenge with code_challenge_method S256 is required")
+	}
+
+	scope, err := grantedScope(client, req.Scope)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	// the user signed in when the session started
+	authTime := time.Now()
+	if sessionId != uuid.Nil {
+		if session, err := e.ISessionRepository.FindById(sessionId); err == nil {
+			authTime = session.CreatedAt
+		}
+	}
+
+	_, err = e.IOAuthRepository.CreateCode(entity.OAuthAuthorizationCode{
+		CodeHash:      helper.HashToken(code),
+		ClientId:      client.ClientId,
+		UserId:        userId,
+		RedirectURI:   req.RedirectURI,
+		Scope:         scope,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      authTime,
+		ExpiresAt:     time.Now().Add(authorizationCodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return AuthorizationRedirect(req.RedirectURI, map[string]string{"code": code, "state": req.State}), nil
+}
+
+// Token implements IOAuthService.
+func (e *OAuthService) Token(req request.TokenRequest, client ClientInfo) (response.OAuthTokenResponse, error) {
+	oauthClient, err := e.authenticateClient(req.ClientId, req.ClientSecret)
+	if err != nil {
+		return response.OAuthTokenResponse{}, err
+	}
+
+	if !oauthClient.AllowsGrant(req.GrantType) {
+		return response.OAuthTokenResponse{}, oauthError("unauthorized_client", "client may not use this grant type")
+	}
+
+	client.OAuthClientId = oauthClient.ClientId
+
+	switch req.GrantType {
+	case entity.GrantAuthorizationCode:
+		return e.exchangeCode(req, oauthClient, client)
+	case entity.GrantRefreshToken:
+		return e.refresh(req, client)
+	case entity.GrantClientCredentials:
+		return e.clientCredentials(req, oauthClient)
+	default:
+		return response.OAuthTokenResponse{}, oauthError("unsupported_grant_type", "unsupported grant type")
+	}
+}
+
+// UserInfo implements IOAuthService. Claims are limited to the profile and
+// email scopes granted to the access token.
+func (e *OAuthService) UserInfo(userId uuid.UUID, scope string) (response.UserInfoResponse, error) {
+	user, err := e.IAuthRepository.FindById(userId)
+	if err != nil {
+		return response.UserInfoResponse{}, err
+	}
+
+	info := response.UserInfoResponse{Sub: user.Id.String()}
+
+	scopes := strings.Fields(scope)
+	if contains(scopes, "profile") {
+		info.Name = user.Name
+		info.PreferredUsername = user.Username
+	}
+	if contains(scopes, "email") {
+		verified := user.EmailVerifiedAt != nil
+		info.Email = user.Email
+		info.EmailVerified = &verified
+	}
+
+	return info, nil
+}
+
+// Introspect implements IOAuthService. Only confidential clients may
+// introspect tokens. Any token issued by this service can be inspected,
+// including those of first-party logins.
+func (e *OAuthService) Introspect(req request.TokenIntrospectionRequest) (response.TokenIntrospectionResponse, error) {
+	oauthClient, err := e.authenticateClient(req.ClientId, req.ClientSecret)
+	if err != nil {
+		return response.TokenIntrospectionResponse{}, err
+	}
+	if !oauthClient.Confidential {
+		return response.TokenIntrospectionResponse{}, ErrInvalidOAuthClient
+	}
+
+	claims, isRefresh, err := e.ITokenService.Introspect(req.Token, req.TokenTypeHint)
+	if err != nil {
+		if errors.Is(err, ErrInactiveToken) {
+			return response.TokenIntrospectionResponse{Active: false}, nil
+		}
+		return response.TokenIntrospectionResponse{}, err
+	}
+
+	res := response.TokenIntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientId:  claims.ClientId,
+		Username:  claims.Username,
+		TokenType: "Bearer",
+		Exp:       claims.ExpiresAt.Unix(),
+		Iat:       claims.IssuedAt.Unix(),
+		Sub:       claims.Subject,
+		Iss:       e.config.Issuer,
+		Jti:       claims.ID,
+		Sid:       claims.SessionId,
+	}
+	if isRefresh {
+		res.TokenType = TokenTypeHintRefresh
+		res.Sid = claims.Family
+	}
+	if res.Sub == "" {
+		res.Sub = claims.Id
+	}
+
+	return res, nil
+}
+
+// Revoke implements IOAuthService. Clients can only revoke tokens issued
+// to them, unknown or invalid tokens are not an error (RFC 7009 section 2.2).
+func (e *OAuthService) Revoke(req request.TokenRevocationRequest) error {
+	oauthClient, err := e.authenticateClient(req.ClientId, req.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	if err := e.ITokenService.RevokeToken(req.Token, req.TokenTypeHint, oauthClient.ClientId); err != nil {
+		if errors.Is(err, ErrTokenNotOwned) {
+			return oauthError("unauthorized_client", "the token was issued to another client")
+		}
+		return err
+	}
+	return nil
+}
+
+// Discovery implements IOAuthService.
+func (e *OAuthService) Discovery() response.OpenIDConfiguration {
+	return response.OpenIDConfiguration{
+		Issuer:                            e.config.Issuer,
+		AuthorizationEndpoint:             e.config.Issuer + "/oauth/authorize",
+		TokenEndpoint:                     e.config.Issuer + "/oauth/token",
+		UserinfoEndpoint:                  e.config.Issuer + "/oauth/userinfo",
+		IntrospectionEndpoint:             e.config.Issuer + "/oauth/introspect",
+		RevocationEndpoint:                e.config.Issuer + "/oauth/revoke",
+		JwksURI:                           e.config.Issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{entity.GrantAuthorizationCode, entity.GrantRefreshToken, entity.GrantClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{helper.AccessKeySet().Algorithm()},
+		ScopesSupported:                   []string{"openid", "profile", "email"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "preferred_username", "email", "email_verified"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	}
+}
+
+// LoginURL implements IOAuthService. returnTo is the authorization request
+// to resume once the user has signed in.
+func (e *OAuthService) LoginURL(returnTo string) string {
+	return AuthorizationRedirect(e.config.LoginURL, map[string]string{"redirect": returnTo})
+}
+
+func (e *OAuthService) exchangeCode(req request.TokenRequest, oauthClient entity.OAuthClient, client ClientInfo) (response.OAuthTokenResponse, error) {
+	var res response.OAuthTokenResponse
+
+	code, err := e.IOAuthRepository.FindCodeByHash(helper.HashToken(req.Code))
+	if err != nil || code.UsedAt != nil || time.Now().After(code.ExpiresAt) {
+		return res, oauthError("invalid_grant", "invalid or expired authorization code")
+	}
+
+	if code.ClientId != oauthClient.ClientId || code.RedirectURI != req.RedirectURI {
+		return res, oauthError("invalid_grant", "authorization code was issued to another client or redirect_uri")
+	}
+
+	challenge := sha256.Sum256([]byte(req.CodeVerifier))
+	expected := base64.RawURLEncoding.EncodeToString(challenge[:])
+	if req.CodeVerifier == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(code.CodeChallenge)) != 1 {
+		return res, oauthError("invalid_grant", "code_verifier does not match the code_challenge")
+	}
+
+	consumed, err := e.IOAuthRepository.ConsumeCode(code.Id, time.Now())
+	if err != nil {
+		return res, err
+	}
+	if !consumed {
+		return res, oauthError("invalid_grant", "invalid or expired authorization code")
+	}
+
+	user, err := e.IAuthRepository.FindById(code.UserId)
+	if err != nil {
+		return res, oauthError("invalid_grant", "user no longer exists")
+	}
+
+	client.Scope = code.Scope
+	tokens, err := e.ITokenService.Issue(user, client)
+	if err != nil {
+		return res, err
+	}
+
+	res = tokenResponse(tokens)
+
+	if contains(strings.Fields(code.Scope), "openid") {
+		res.IDToken, err = helper.GenerateIDToken(user, e.config.Issuer, oauthClient.ClientId, code.Nonce, code.Scope, code.AuthTime)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+func (e *OAuthService) refresh(req request.TokenRequest, client ClientInfo) (response.OAuthTokenResponse, error) {
+	tokens, err := e.ITokenService.Rotate(req.RefreshToken, client)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+			return response.OAuthTokenResponse{}, oauthError("invalid_grant", err.Error())
+		}
+		return response.OAuthTokenResponse{}, err
+	}
+
+	return tokenResponse(tokens), nil
+}
+
+func (e *OAuthService) clientCredentials(req request.TokenRequest, oauthClient entity.OAuthClient) (response.OAuthTokenResponse, error) {
+	if !oauthClient.Confidential {
+		return response.OAuthTokenResponse{}, oauthError("unauthorized_client", "client_credentials requires a confidential client")
+	}
+
+	scope, err := grantedScope(oauthClient, req.Scope)
+	if err != nil {
+		return response.OAuthTokenResponse{}, err
+	}
+
+	accessToken, err := helper.GenerateClientAccessToken(oauthClient.ClientId, scope)
+	if err != nil {
+		return response.OAuthTokenResponse{}, err
+	}
+
+	return tokenResponse(response.TokenPair{AccessToken: accessToken, Scope: scope}), nil
+}
+
+// authenticateClient checks the client credentials. Public clients only
+// identify themselves, confidential clients must present their secret.
+func (e *OAuthService) authenticateClient(clientId, secret string) (entity.OAuthClient, error) {
+	client, err := e.IOAuthRepository.FindClientByClientId(clientId)
+	if err != nil {
+		return client, ErrInvalidOAuthClient
+	}
+
+	if !client.Confidential {
+		if secret != "" {
+			return client, ErrInvalidOAuthClient
+		}
+		return client, nil
+	}
+
+	if secret == "" || subtle.ConstantTimeCompare([]byte(helper.HashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return client, ErrInvalidOAuthClient
+	}
+
+	return client, nil
+}
+
+// grantedScope checks the requested scope against the client's allowed
+// scopes. An empty request grants all of them.
+func grantedScope(client entity.OAuthClient, requested string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return client.Scopes, nil
+	}
+
+	scopes := unique(strings.Fields(requested))
+	for _, scope := range scopes {
+		if !client.AllowsScope(scope) {
+			return "", oauthError("invalid_scope", "scope not allowed for this client: "+scope)
+		}
+	}
+	return strings.Join(scopes, " "), nil
+}
+
+// AuthorizationRedirect appends the non-empty params to the query of uri.
+func AuthorizationRedirect(uri string, params map[string]string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+
+	query := parsed.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func tokenResponse(tokens response.TokenPair) response.OAuthTokenResponse {
+	return response.OAuthTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(helper.AccessTokenTTL.Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        tokens.Scope,
+	}
+}
+
+func toOAuthClientResponse(client entity.OAuthClient) response.OAuthClientResponse {
+	return response.OAuthClientResponse{
+		Id:           client.Id,
+		ClientId:     client.ClientId,
+		Name:         client.Name,
+		RedirectURIs: strings.Fields(client.RedirectURIs),
+		Scopes:       strings.Fields(client.Scopes),
+		GrantTypes:   strings.Fields(client.GrantTypes),
+		Confidential: client.Confidential,
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/password"
+	"github.com/google/uuid"
+)
+
+// IPasswordPolicyService applies the password policy wherever a user picks
+// a new password.
+type IPasswordPolicyService interface {
+	// Check returns a *password.PolicyError when the password breaks the
+	// policy for the user. New users have no Id and no history.
+	Check(ctx context.Context, user entity.User, plain string) error
+	// Remember adds the hash of a newly set password to the user's history.
+	Remember(userId uuid.UUID, hashed string)
+}
+
+type PasswordPolicyService struct {
+	IPasswordHistoryRepository repository.IPasswordHistoryRepository
+	Policy                     *password.Policy
+}
+
+func NewPasswordPolicyService(repo repository.IPasswordHistoryRepository, policy *password.Policy) IPasswordPolicyService {
+	return &PasswordPolicyService{
+		IPasswordHistoryRepository: repo,
+		Policy:                     policy,
+	}
+}
+
+// Check implements IPasswordPolicyService.
+func (e *PasswordPolicyService) Check(ctx context.Context, user entity.User, plain string) error {
+	subject := password.PolicyContext{
+		Username: user.Username,
+		Email:    user.Email,
+		Name:     user.Name,
+	}
+
+	if size := e.Policy.HistorySize(); size > 0 && user.Id != uuid.Nil {
+		history, err := e.IPasswordHistoryRepository.FindRecentByUserId(user.Id, size)
+		if err != nil {
+			return err
+		}
+		for _, value := range history {
+			subject.History = append(subject.History, value.Password)
+		}
+	}
+
+	return e.Policy.CheckContext(ctx, plain, subject)
+}
+
+// Remember implements IPasswordPolicyService. Failures are only logged, the
+// password has already been changed.
+func (e *PasswordPolicyService) Remember(userId uuid.UUID, hashed string) {
+	size := e.Policy.HistorySize()
+	if size == 0 {
+		return
+	}
+
+	log := logger.GetLogger()
+
+	if err := e.IPasswordHistoryRepository.Create(entity.PasswordHistory{UserId: userId, Password: hashed}); err != nil {
+		log.WithError(err).Error("failed to store password history: " + userId.String())
+		return
+	}
+
+	if err := e.IPasswordHistoryRepository.Prune(userId, size); err != nil {
+		log.WithError(err).Error("failed to prune password history: " + userId.String())
+	}
+}
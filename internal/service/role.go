@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownRole       = errors.New("unknown role")
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+type IRoleService interface {
+	Create(req request.RoleCreateRequest) (response.RoleResponse, error)
+	FindAll() ([]response.RoleResponse, error)
+	FindById(reqId uuid.UUID) (response.RoleResponse, error)
+	Update(req request.RoleUpdateRequest) (response.RoleResponse, error)
+	Delete(reqId uuid.UUID) error
+	FindAllPermissions() ([]response.PermissionResponse, error)
+	AssignToUser(req request.UserRolesRequest) error
+}
+
+type RoleService struct {
+	IRoleRepository repository.IRoleRepository
+	validate        *validator.Validate
+}
+
+func NewRoleService(repo repository.IRoleRepository, validate *validator.Validate) IRoleService {
+	return &RoleService{
+		IRoleRepository: repo,
+		validate:        validate,
+	}
+}
+
+// Create implements IRoleService.
+func (e *RoleService) Create(req request.RoleCreateRequest) (response.RoleResponse, error) {
+	if err := e.validate.Struct(req); err != nil {
+		return response.RoleResponse{}, err
+	}
+
+	permissions, err := e.findPermissions(req.Permissions)
+	if err != nil {
+		return response.RoleResponse{}, err
+	}
+
+	role, err := e.IRoleRepository.Create(entity.Role{
+		Name:        strings.ToLower(req.Name),
+		Description: req.Description,
+		Permissions: permissions,
+	})
+	if err != nil {
+		return response.RoleResponse{}, err
+	}
+
+	return toRoleResponse(role), nil
+}
+
+// FindAll implements IRoleService.
+func (e *RoleService) FindAll() ([]response.RoleResponse, error) {
+	roles, err := e.IRoleRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	resps := []response.RoleResponse{}
+	for _, value := range roles {
+		resps = append(resps, toRoleResponse(value))
+	}
+	return resps, nil
+}
+
+// FindById implements IRoleService.
+func (e *RoleService) FindById(reqId uuid.UUID) (response.RoleResponse, error) {
+	role, err := e.IRoleRepository.FindById(reqId)
+	if err != nil {
+		return response.RoleResponse{}, err
+	}
+	return toRoleResponse(role), nil
+}
+
+// Update implements IRoleService.
+func (e *RoleService) Update(req request.RoleUpdateRequest) (response.RoleResponse, error) {
+	if err := e.validate.Struct(req); err != nil {
+		return response.RoleResponse{}, err
+	}
+
+	role, err := e.IRoleRepository.FindById(req.Id)
+	if err != nil {
+		return response.RoleResponse{}, err
+	}
+
+	permissions, err := e.findPermissions(req.Permissions)
+	if err != nil {
+		return response.RoleResponse{}, err
+	}
+
+	role.Name = strings.ToLower(req.Name)
+	role.Description = req.Description
+	role.Permissions = permissions
+
+	if err := e.IRoleRepository.Update(role); err != nil {
+		return response.RoleResponse{}, err
+	}
+
+	return toRoleResponse(role), nil
+}
+
+// Delete implements IRoleService.
+func (e *RoleService) Delete(reqId uuid.UUID) error {
+	if _, err := e.IRoleRepository.FindById(reqId); err != nil {
+		return err
+	}
+	return e.IRoleRepository.Delete(reqId)
+}
+
+// FindAllPermissions implements IRoleService.
+func (e *RoleService) FindAllPermissions() ([]response.PermissionResponse, error) {
+	permissions, err := e.IRoleRepository.FindAllPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	resps := []response.PermissionResponse{}
+	for _, value := range permissions {
+		resps = append(resps, response.PermissionResponse{
+			Name:        value.Name,
+			Description: value.Description,
+		})
+	}
+	return resps, nil
+}
+
+// AssignToUser implements IRoleService. The user's roles are replaced by
+// the requested ones, changes apply from the user's next token refresh.
+func (e *RoleService) AssignToUser(req request.UserRolesRequest) error {
+	names := []string{}
+	for _, name := range req.Roles {
+		names = append(names, strings.ToLower(name))
+	}
+
+	roles := []entity.Role{}
+	if len(names) > 0 {
+		found, err := e.IRoleRepository.FindByNames(names)
+		if err != nil {
+			return err
+		}
+		if len(found) != len(unique(names)) {
+			return ErrUnknownRole
+		}
+		roles = found
+	}
+
+	return e.IRoleRepository.AssignToUser(req.UserId, roles)
+}
+
+func (e *RoleService) findPermissions(names []string) ([]entity.Permission, error) {
+	permissions := []entity.Permission{}
+	if len(names) == 0 {
+		return permissions, nil
+	}
+
+	found, err := e.IRoleRepository.FindPermissionsByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found) != len(unique(names)) {
+		known := map[string]bool{}
+		for _, permission := range found {
+			known[permission.Name] = true
+		}
+		for _, name := range names {
+			if !known[name] {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+			}
+		}
+	}
+
+	return found, nil
+}
+
+func toRoleResponse(role entity.Role) response.RoleResponse {
+	permissions := []string{}
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+
+	return response.RoleResponse{
+		Id:          role.Id,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+func unique(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type ISecurityEventService interface {
+	Record(event string, userId *uuid.UUID, client ClientInfo, details string)
+}
+
+type SecurityEventService struct {
+	ISecurityEventRepository repository.ISecurityEventRepository
+}
+
+func NewSecurityEventService(repo repository.ISecurityEventRepository) ISecurityEventService {
+	return &SecurityEventService{ISecurityEventRepository: repo}
+}
+
+// Record implements ISecurityEventService. The event is stored and logged
+// as a warning. Storage failures are logged only, recording an event must
+// never fail the request that triggered it.
+func (e *SecurityEventService) Record(event string, userId *uuid.UUID, client ClientInfo, details string) {
+	log := logger.GetLogger().WithFields(logrus.Fields{
+		"event":      event,
+		"ip":         client.IP,
+		"user_agent": client.UserAgent,
+	})
+	if userId != nil {
+		log = log.WithField("user_id", userId.String())
+	}
+
+	log.Warn("security event: " + details)
+
+	_, err := e.ISecurityEventRepository.Create(entity.SecurityEvent{
+		Event:     event,
+		UserId:    userId,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Details:   details,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to store security event")
+	}
+}
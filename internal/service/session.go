@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked")
+)
+
+type ISessionService interface {
+	Start(userId uuid.UUID, client ClientInfo) (entity.Session, error)
+	Touch(sessionId uuid.UUID) error
+	FindAllByUserId(userId, currentId uuid.UUID) ([]response.SessionResponse, error)
+	Revoke(userId, sessionId uuid.UUID) error
+	RevokeAll(userId uuid.UUID) error
+}
+
+type SessionService struct {
+	ISessionRepository repository.ISessionRepository
+	TokenStore         tokenstore.TokenStore
+}
+
+func NewSessionService(repo repository.ISessionRepository, tokenStore tokenstore.TokenStore) ISessionService {
+	return &SessionService{
+		ISessionRepository: repo,
+		TokenStore:         tokenStore,
+	}
+}
+
+// Start implements ISessionService.
+func (e *SessionService) Start(userId uuid.UUID, client ClientInfo) (entity.Session, error) {
+	now := time.Now()
+
+	return e.ISessionRepository.Create(entity.Session{
+		UserId:     userId,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		Country:    client.Country,
+		City:       client.City,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(helper.RefreshTokenTTL),
+	})
+}
+
+// Touch implements ISessionService. It records activity on the session and
+// extends it by another refresh token lifetime.
+func (e *SessionService) Touch(sessionId uuid.UUID) error {
+	session, err := e.ISessionRepository.FindById(sessionId)
+	if err != nil {
+		return ErrSessionNotFound
+	}
+
+	if !session.IsActive() {
+		return ErrSessionRevoked
+	}
+
+	now := time.Now()
+	return e.ISessionRepository.Touch(session.Id, now, now.Add(helper.RefreshTokenTTL))
+}
+
+// FindAllByUserId implements ISessionService.
+func (e *SessionService) FindAllByUserId(userId, currentId uuid.UUID) ([]response.SessionResponse, error) {
+	sessions, err := e.ISessionRepository.FindActiveByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	resps := []response.SessionResponse{}
+	for _, value := range sessions {
+		resps = append(resps, response.SessionResponse{
+			Id:         value.Id,
+			IP:         value.IP,
+			UserAgent:  value.UserAgent,
+			Country:    value.Country,
+			City:       value.City,
+			Current:    value.Id == currentId,
+			CreatedAt:  value.CreatedAt,
+			LastSeenAt: value.LastSeenAt,
+			ExpiresAt:  value.ExpiresAt,
+		})
+	}
+
+	return resps, nil
+}
+
+// Revoke implements ISessionService. Only sessions owned by userId can be
+// revoked. Tokens of the session stop working immediately.
+func (e *SessionService) Revoke(userId, sessionId uuid.UUID) error {
+	session, err := e.ISessionRepository.FindById(sessionId)
+	if err != nil || session.UserId != userId {
+		return ErrSessionNotFound
+	}
+
+	if err := e.ISessionRepository.Revoke(session.Id, time.Now()); err != nil {
+		return err
+	}
+
+	return e.TokenStore.RevokeFamily(session.Id.String(), time.Now().Add(helper.RefreshTokenTTL))
+}
+
+// RevokeAll implements ISessionService. Every token issued to the user so
+// far stops working, including those of the current session.
+func (e *SessionService) RevokeAll(userId uuid.UUID) error {
+	if err := e.ISessionRepository.RevokeAllByUserId(userId, time.Now()); err != nil {
+		return err
+	}
+
+	return e.TokenStore.RevokeUser(userId.String(), time.Now().Add(helper.RefreshTokenTTL))
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+	ErrInactiveToken       = errors.New("token is not active")
+	ErrTokenNotOwned       = errors.New("token was issued to another client")
+)
+
+const (
+	TokenTypeHintAccess  = "access_token"
+	TokenTypeHintRefresh = "refresh_token"
+)
+
+type ITokenService interface {
+	Issue(user entity.User, client ClientInfo) (response.TokenPair, error)
+	Rotate(refreshToken string, client ClientInfo) (response.TokenPair, error)
+	Revoke(accessToken, refreshToken string) error
+	Introspect(token, hint string) (*helper.Claims, bool, error)
+	RevokeToken(token, hint, clientId string) error
+}
+
+// ClientInfo describes the client a request came from. Device is the hash
+// of the device cookie, empty when the client has none. Country and City
+// are resolved from the IP when GeoIP is configured. OAuthClientId and
+// Scope are set when tokens are requested through the OAuth endpoints and
+// are carried by the issued tokens.
+type ClientInfo struct {
+	IP            string
+	UserAgent     string
+	Device        string
+	Country       string
+	City          string
+	OAuthClientId string
+	Scope         string
+}
+
+type TokenService struct {
+	IAuthRepository       repository.IAuthRepository
+	ISessionService       ISessionService
+	ISecurityEventService ISecurityEventService
+	TokenStore            tokenstore.TokenStore
+}
+
+func NewTokenService(repo repository.IAuthRepository, sessionServ ISessionService, events ISecurityEventService, tokenStore tokenstore.TokenStore) ITokenService {
+	return &TokenService{
+		IAuthRepository:       repo,
+		ISessionService:       sessionServ,
+		ISecurityEventService: events,
+		TokenStore:            tokenStore,
+	}
+}
+
+// Issue implements ITokenService. It starts a new session, whose id is the
+// family of the refresh tokens rotated from it.
+func (e *TokenService) Issue(user entity.User, client ClientInfo) (response.TokenPair, error) {
+	session, err := e.ISessionService.Start(user.Id, client)
+	if err != nil {
+		return response.TokenPair{}, err
+	}
+
+	return e.issue(user, session.Id.String(), client.OAuthClientId, client.Scope)
+}
+
+// Rotate implements ITokenService. The presented refresh token is revoked
+// and replaced by a new one of the same family. Presenting a token that was
+// already rotated means it leaked, so the whole family is revoked, a
+// security event is logged and ErrRefreshTokenReused is returned. Tokens
+// issued to an OAuth client can only be rotated by that client.
+func (e *TokenService) Rotate(refreshToken string, client ClientInfo) (response.TokenPair, error) {
+	var pair response.TokenPair
+
+	claims, err := helper.ParseToken(refreshToken, true)
+	if err != nil {
+		return pair, ErrInvalidRefreshToken
+	}
+
+	jti, family, userId := claims.ID, claims.Family, claims.Id
+
+	if claims.ClientId != client.OAuthClientId {
+		return pair, ErrInvalidRefreshToken
+	}
+
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return pair, ErrInvalidRefreshToken
+	}
+
+	sessionId, err := uuid.Parse(family)
+	if err != nil {
+		return pair, ErrInvalidRefreshToken
+	}
+
+	revoked, err := e.TokenStore.IsFamilyRevoked(family)
+	if err != nil {
+		return pair, err
+	}
+	if revoked {
+		return pair, ErrInvalidRefreshToken
+	}
+
+	revoked, err = e.TokenStore.IsUserRevoked(userId, claims.IssuedAt.Time)
+	if err != nil {
+		return pair, err
+	}
+	if revoked {
+		return pair, ErrInvalidRefreshToken
+	}
+
+	consumed, err := e.TokenStore.Consume(jti, claims.ExpiresAt.Time)
+	if err != nil {
+		return pair, err
+	}
+	if !consumed {
+		if err := e.ISessionService.Revoke(id, sessionId); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return pair, err
+		}
+
+		e.ISecurityEventService.Record(entity.SecurityEventRefreshTokenReuse, &id, client,
+			"refresh token reuse detected, token family "+family+" revoked")
+
+		return pair, ErrRefreshTokenReused
+	}
+
+	if err := e.ISessionService.Touch(sessionId); err != nil {
+		if errors.Is(err, ErrSessionNotFound) || errors.Is(err, ErrSessionRevoked) {
+			return pair, ErrInvalidRefreshToken
+		}
+		return pair, err
+	}
+
+	// reload the user so role and permission changes reach the new tokens
+	user, err := e.IAuthRepository.FindById(id)
+	if err != nil {
+		return pair, ErrInvalidRefreshToken
+	}
+
+	return e.issue(user, family, claims.ClientId, claims.Scope)
+}
+
+// Revoke implements ITokenService. It ends the session of the refresh
+// token. Invalid or expired tokens are ignored.
+func (e *TokenService) Revoke(accessToken, refreshToken string) error {
+	if claims, err := helper.ParseToken(refreshToken, true); err == nil {
+		id, idErr := uuid.Parse(claims.Id)
+		sessionId, sessionErr := uuid.Parse(claims.Family)
+		if idErr == nil && sessionErr == nil {
+			if err := e.ISessionService.Revoke(id, sessionId); err != nil && !errors.Is(err, ErrSessionNotFound) {
+				return err
+			}
+		}
+	}
+
+	if claims, err := helper.ParseToken(accessToken, false); err == nil {
+		if err := e.TokenStore.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Introspect implements ITokenService. It returns the claims of an active
+// access or refresh token and whether it is a refresh token. The hint only
+// decides which kind is tried first. Tokens that are invalid, expired or
+// revoked yield ErrInactiveToken.
+func (e *TokenService) Introspect(token, hint string) (*helper.Claims, bool, error) {
+	claims, isRefresh, err := parseAny(token, hint)
+	if err != nil {
+		return nil, false, ErrInactiveToken
+	}
+
+	family := claims.SessionId
+	if isRefresh {
+		family = claims.Family
+	}
+
+	revoked, err := tokenstore.IsTokenRevoked(e.TokenStore, claims.ID, claims.Id, family, claims.IssuedAt.Time)
+	if err != nil {
+		return nil, false, err
+	}
+	if revoked {
+		return nil, false, ErrInactiveToken
+	}
+
+	return claims, isRefresh, nil
+}
+
+// RevokeToken implements ITokenService for OAuth clients. A client can only
+// revoke tokens issued to it. Revoking a refresh token ends its session,
+// which revokes the access tokens of that session as well. Invalid tokens
+// are ignored.
+func (e *TokenService) RevokeToken(token, hint, clientId string) error {
+	claims, isRefresh, err := parseAny(token, hint)
+	if err != nil {
+		return nil
+	}
+
+	if claims.ClientId != clientId {
+		return ErrTokenNotOwned
+	}
+
+	if !isRefresh {
+		return e.TokenStore.Revoke(claims.ID, claims.ExpiresAt.Time)
+	}
+
+	id, idErr := uuid.Parse(claims.Id)
+	sessionId, sessionErr := uuid.Parse(claims.Family)
+	if idErr != nil || sessionErr != nil {
+		return nil
+	}
+
+	if err := e.ISessionService.Revoke(id, sessionId); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (e *TokenService) issue(user entity.User, family, clientId, scope string) (response.TokenPair, error) {
+	var pair response.TokenPair
+
+	accessToken, err := helper.GenerateAccessToken(user, family, clientId, scope)
+	if err != nil {
+		return pair, err
+	}
+
+	refreshToken, err := helper.GenerateRefreshToken(user, family, clientId, scope)
+	if err != nil {
+		return pair, err
+	}
+
+	return response.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}, nil
+}
+
+// parseAny parses the token as an access or a refresh token, trying the
+// hinted kind first.
+func parseAny(token, hint string) (*helper.Claims, bool, error) {
+	order := []bool{false, true}
+	if hint == TokenTypeHintRefresh {
+		order = []bool{true, false}
+	}
+
+	var err error
+	for _, isRefresh := range order {
+		var claims *helper.Claims
+		if claims, err = helper.ParseToken(token, isRefresh); err == nil {
+			return claims, isRefresh, nil
+		}
+	}
+	return nil, false, err
+}
@@ -1,7 +1,8 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"strings"
 
 	"github.com/fatihrizqon/go-fiber-service/helper"
@@ -9,55 +10,65 @@ import (
 	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
 	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
 	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/password"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type IUserService interface {
-	Create(req request.UserCreateRequest) (entity.User, error)
+	Create(ctx context.Context, req request.UserCreateRequest) (entity.User, error)
 	FindAll(page, pageSize int, search string, options helper.SearchOptions, filters entity.UserFilters) ([]response.UserResponse, int, error)
 	FindById(reqId uuid.UUID) (response.UserResponse, error)
-	Update(req request.UserUpdateRequest) (entity.User, error)
+	Update(ctx context.Context, req request.UserUpdateRequest) (entity.User, error)
 	Delete(reqId uuid.UUID) (entity.User, error)
 }
 type UserService struct {
-	IUserRepository repository.IUserRepository
-	validate        *validator.Validate
+	IUserRepository        repository.IUserRepository
+	AccountMailer          *AccountMailer
+	Hasher                 password.Hasher
+	IPasswordPolicyService IPasswordPolicyService
+	validate               *validator.Validate
 }
 
-func NewUserService(repo repository.IUserRepository, validate *validator.Validate) IUserService {
+func NewUserService(repo repository.IUserRepository, accountMailer *AccountMailer, hasher password.Hasher, policyServ IPasswordPolicyService, validate *validator.Validate) IUserService {
 	return &UserService{
-		IUserRepository: repo,
-		validate:        validate,
+		IUserRepository:        repo,
+		AccountMailer:          accountMailer,
+		Hasher:                 hasher,
+		IPasswordPolicyService: policyServ,
+		validate:               validate,
 	}
 }
 
 // Create implements IUserService.
-func (e *UserService) Create(req request.UserCreateRequest) (entity.User, error) {
-	var user entity.User
-
-	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), 14)
-	if err != nil {
-		return user, errors.New("failed to hash password")
-	}
-
+func (e *UserService) Create(ctx context.Context, req request.UserCreateRequest) (entity.User, error) {
 	entity := entity.User{
 		Username: strings.ToLower(req.Username),
 		Name:     req.Name,
 		Email:    strings.ToLower(strings.TrimSpace(req.Email)),
-		Password: string(hashed),
 	}
 
 	if err := e.validate.Struct(req); err != nil {
 		return entity, err
 	}
 
+	if err := e.IPasswordPolicyService.Check(ctx, entity, req.Password); err != nil {
+		return entity, err
+	}
+
+	hashed, err := password.HashContext(ctx, e.Hasher, req.Password)
+	if err != nil {
+		return entity, fmt.Errorf("failed to hash password: %w", err)
+	}
+	entity.Password = hashed
+
 	entity, err = e.IUserRepository.Create(entity)
 	if err != nil {
 		return entity, err
 	}
 
+	e.IPasswordPolicyService.Remember(entity.Id, hashed)
+
 	return entity, nil
 }
 
@@ -81,13 +92,14 @@ func (e *UserService) FindAll(page, pageSize int, search string, options helper.
 
 	for _, value := range entities {
 		resp := response.UserResponse{
-			Id:        value.Id,
-			Username:  value.Username,
-			Name:      value.Name,
-			Email:     value.Email,
-			Status:    value.Status,
-			CreatedAt: value.CreatedAt,
-			UpdatedAt: value.UpdatedAt,
+			Id:              value.Id,
+			Username:        value.Username,
+			Name:            value.Name,
+			Email:           value.Email,
+			Status:          value.Status,
+			EmailVerifiedAt: value.EmailVerifiedAt,
+			CreatedAt:       value.CreatedAt,
+			UpdatedAt:       value.UpdatedAt,
 		}
 		resps = append(resps, resp)
 	}
@@ -105,33 +117,40 @@ func (e *UserService) FindById(reqId uuid.UUID) (response.UserResponse, error) {
 	}
 
 	return response.UserResponse{
-		Id:        result.Id,
-		Username:  result.Username,
-		Name:      result.Name,
-		Email:     result.Email,
-		Status:    result.Status,
-		CreatedAt: result.CreatedAt,
-		UpdatedAt: result.UpdatedAt,
+		Id:              result.Id,
+		Username:        result.Username,
+		Name:            result.Name,
+		Email:           result.Email,
+		Status:          result.Status,
+		EmailVerifiedAt: result.EmailVerifiedAt,
+		CreatedAt:       result.CreatedAt,
+		UpdatedAt:       result.UpdatedAt,
 	}, nil
 }
 
 // Update implements IUserService.
-func (e *UserService) Update(req request.UserUpdateRequest) (entity.User, error) {
+func (e *UserService) Update(ctx context.Context, req request.UserUpdateRequest) (entity.User, error) {
 	entity, err := e.IUserRepository.FindById(req.Id)
 	if err != nil {
 		return entity, err
 	}
 
+	emailChanged := !strings.EqualFold(entity.Email, req.Email)
+
 	entity.Username = strings.ToLower(req.Username)
 	entity.Name = req.Name
 	entity.Email = req.Email
 
 	if req.Password != "" {
-		hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), 14)
+		if err := e.IPasswordPolicyService.Check(ctx, entity, req.Password); err != nil {
+			return entity, err
+		}
+
+		hashed, err := password.HashContext(ctx, e.Hasher, req.Password)
 		if err != nil {
-			return entity, errors.New("failed to generate password")
+			return entity, fmt.Errorf("failed to generate password: %w", err)
 		}
-		entity.Password = string(hashed)
+		entity.Password = hashed
 	}
 
 	err = e.IUserRepository.Update(entity)
@@ -139,6 +158,18 @@ func (e *UserService) Update(req request.UserUpdateRequest) (entity.User, error)
 		return entity, err
 	}
 
+	if req.Password != "" {
+		e.IPasswordPolicyService.Remember(entity.Id, entity.Password)
+	}
+
+	if emailChanged {
+		if err := e.IUserRepository.ResetEmailVerification(entity.Id); err != nil {
+			return entity, err
+		}
+		entity.EmailVerifiedAt = nil
+		go sendVerification(e.AccountMailer, entity)
+	}
+
 	entity.Password = ""
 	return entity, nil
 }
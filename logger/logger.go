@@ -12,14 +12,21 @@ import (
 )
 
 var log *logrus.Logger
-var webhookUrl = "https://discord.com/api/webhooks/1293823988316901386/SJhJv_wvyYX36DRFwQJyfZtHH5BVKatSRFzr5tdsPWslri87_dFLbNL16zkHZ7wZtBrh"
+
+// Config configures the logger. Entries are written to File, and posted to
+// the Discord WebhookURL as well when it is set.
+type Config struct {
+	Level      string
+	File       string
+	WebhookURL string
+}
 
 // Init initializes the logger
-func Init() {
+func Init(config Config) {
 	log = logrus.New()
 
 	// Open a file for writing logs
-	logFile, err := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	logFile, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
 		log.Fatal("Error opening log file:", err)
 	}
@@ -28,7 +35,9 @@ func Init() {
 	log.SetOutput(logFile)
 
 	// Set log level
-	log.SetLevel(logrus.InfoLevel)
+	if err := SetLogLevel(config.Level); err != nil {
+		log.Fatal("Invalid log level:", config.Level)
+	}
 
 	// Set log format
 	log.SetFormatter(&logrus.TextFormatter{
@@ -36,18 +45,63 @@ func Init() {
 	})
 
 	// Add webhook if URL is defined
-	if webhookUrl != "" {
-		log.AddHook(&WebHook{})
+	if config.WebhookURL != "" {
+		log.AddHook(&WebHook{URL: config.WebhookURL})
+	}
+}
+
+// SetLocator makes every entry carrying an ip field also carry its
+// location, for the file log and the Discord webhook alike. locate returns
+// an empty string for unknown addresses.
+func SetLocator(locate func(ip string) string) {
+	log := GetLogger()
+
+	// the location has to be set before the other hooks see the entry
+	hooks := make(logrus.LevelHooks)
+	hooks.Add(&locationHook{locate: locate})
+	for level, levelHooks := range log.Hooks {
+		hooks[level] = append(hooks[level], levelHooks...)
 	}
+	log.ReplaceHooks(hooks)
 }
 
-// GetLogger returns the logger instance
+type locationHook struct {
+	locate func(ip string) string
+}
+
+func (h *locationHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *locationHook) Fire(entry *logrus.Entry) error {
+	if _, ok := entry.Data["location"]; ok {
+		return nil
+	}
+
+	ip, _ := entry.Data["ip"].(string)
+	if ip == "" {
+		return nil
+	}
+
+	if location := h.locate(ip); location != "" {
+		entry.Data["location"] = location
+	}
+	return nil
+}
+
+// GetLogger returns the logger instance, or the standard logrus logger
+// when Init has not run, as in tests
 func GetLogger() *logrus.Logger {
+	if log == nil {
+		return logrus.StandardLogger()
+	}
 	return log
 }
 
 // WebHook sends log messages to Discord
-type WebHook struct{}
+type WebHook struct {
+	URL string
+}
 
 // Levels returns the log levels to be sent to Discord
 func (h *WebHook) Levels() []logrus.Level {
@@ -138,7 +192,7 @@ func (h *WebHook) Fire(entry *logrus.Entry) error {
 		return err
 	}
 
-	resp, err := http.Post(webhookUrl, "application/json", bytes.NewBuffer(payload))
+	resp, err := http.Post(h.URL, "application/json", bytes.NewBuffer(payload))
 	if err != nil {
 		return err
 	}
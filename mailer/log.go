@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// LogMailer writes messages to a writer instead of delivering them. It does
+// not go through logrus so that message bodies never reach the log webhook.
+type LogMailer struct {
+	mu   sync.Mutex
+	out  io.Writer
+	from string
+}
+
+func NewLogMailer(out io.Writer, from string) *LogMailer {
+	return &LogMailer{out: out, from: from}
+}
+
+// Send implements Mailer.
+func (m *LogMailer) Send(message Message) error {
+	if message.From == "" {
+		message.From = m.from
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.out, "----- mail -----\r\n%s----- end mail -----\r\n", format(message))
+	return err
+}
@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages through a concrete backend.
+type Mailer interface {
+	Send(message Message) error
+}
+
+// Config selects and configures the mail backend.
+type Config struct {
+	Driver       string
+	From         string
+	Path         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// New returns the Mailer for the configured driver. The log driver is used
+// when none is set so that local development never sends real mail.
+func New(config Config) (Mailer, error) {
+	switch config.Driver {
+	case "", "log":
+		return NewLogMailer(os.Stdout, config.From), nil
+	case "file":
+		return NewFileMailer(config.Path, config.From)
+	case "smtp":
+		return NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", config.Driver)
+	}
+}
+
+func format(message Message) []byte {
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		message.From, message.To, message.Subject, message.Body))
+}
@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer delivers messages through an SMTP relay.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send implements Mailer.
+func (m *SMTPMailer) Send(message Message) error {
+	if message.From == "" {
+		message.From = m.from
+	}
+
+	return smtp.SendMail(m.addr, m.auth, message.From, []string{message.To}, format(message))
+}
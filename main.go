@@ -1,9 +1,14 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 
+	"github.com/fatihrizqon/go-fiber-service/config"
 	_ "github.com/fatihrizqon/go-fiber-service/docs"
 	"github.com/fatihrizqon/go-fiber-service/logger"
 	"github.com/fatihrizqon/go-fiber-service/router"
@@ -23,26 +28,33 @@ import (
 // @host 127.0.0.1:3000
 // @BasePath /
 func main() {
-	logger.Init()
-
-	if logLevel := "info"; logLevel != "" {
-		if err := logger.SetLogLevel(logLevel); err != nil {
-			log.Fatalf("Invalid log level: %s", logLevel)
-		}
+	env, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
 
+	logger.Init(logger.Config{
+		Level:      env.LogLevel,
+		File:       env.LogFile,
+		WebhookURL: env.DiscordWebhookURL,
+	})
+	logger.GetLogger().Debug("configuration:\n" + env.String())
+
 	fmt.Println("Starting the server...")
 	app := fiber.New()
 
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "http://localhost:5173",
+		AllowOrigins:     strings.Join(env.CORSAllowOrigins, ","),
 		AllowMethods:     "GET,POST,HEAD,PUT,DELETE",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-CSRF-Token",
 		AllowCredentials: true,
 	}))
 
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
-	router.NewRouter(app)
-	log.Fatal(app.Listen("127.0.0.1:3000"))
+	router.NewRouter(app, env)
+	log.Fatal(app.Listen(env.AppAddress))
 }
@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNoToken      = errors.New("no token provided")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// TokenSource extracts a raw access token from the request. It returns an
+// empty string when the request carries no token in that place.
+type TokenSource func(c *fiber.Ctx) string
+
+// FromCookie reads the token from the named cookie.
+func FromCookie(name string) TokenSource {
+	return func(c *fiber.Ctx) string {
+		return c.Cookies(name)
+	}
+}
+
+// FromAuthHeader reads the token from the Authorization header using the
+// given scheme, e.g. "Bearer".
+func FromAuthHeader(scheme string) TokenSource {
+	prefix := scheme + " "
+	return func(c *fiber.Ctx) string {
+		header := c.Get(fiber.HeaderAuthorization)
+		if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+			return ""
+		}
+		return strings.TrimSpace(header[len(prefix):])
+	}
+}
+
+// FromQuery reads the token from the named query parameter. Browsers cannot
+// set headers on WebSocket handshakes, so it is only honoured on upgrade
+// requests to keep tokens out of regular URLs and access logs.
+func FromQuery(param string) TokenSource {
+	return func(c *fiber.Ctx) string {
+		if !strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket") {
+			return ""
+		}
+		return c.Query(param)
+	}
+}
+
+// ApiTokenVerifier resolves the principal of a personal access token. It
+// returns an error when the token is unknown or expired.
+type ApiTokenVerifier func(token string) (*Principal, error)
+
+// Authenticator verifies access tokens. Every middleware that needs to know
+// who is calling goes through it, so they all agree on what a valid token
+// is.
+type Authenticator struct {
+	store   tokenstore.TokenStore
+	sources []TokenSource
+
+	apiTokenPrefix string
+	apiTokens      ApiTokenVerifier
+}
+
+// NewAuthenticator returns an authenticator trying the sources in order.
+// Without sources it reads the Bearer Authorization header and then the
+// access_token cookie. The header goes first: CSRF protection skips
+// requests carrying it, so they must not fall back to the cookie.
+func NewAuthenticator(store tokenstore.TokenStore, sources ...TokenSource) *Authenticator {
+	if len(sources) == 0 {
+		sources = []TokenSource{FromAuthHeader("Bearer"), FromCookie("access_token")}
+	}
+
+	return &Authenticator{store: store, sources: sources}
+}
+
+// WithApiTokens makes the authenticator accept personal access tokens from
+// the same sources as JWTs. Tokens starting with prefix are handed to
+// verify instead of being parsed as JWTs.
+func (a *Authenticator) WithApiTokens(prefix string, verify ApiTokenVerifier) *Authenticator {
+	a.apiTokenPrefix = prefix
+	a.apiTokens = verify
+	return a
+}
+
+// Authenticate resolves the principal of the request. It returns ErrNoToken
+// when no source yields a token and ErrInvalidToken when the token does not
+// verify or has been revoked.
+func (a *Authenticator) Authenticate(c *fiber.Ctx) (*Principal, error) {
+	token := a.extract(c)
+	if token == "" {
+		return nil, ErrNoToken
+	}
+
+	if a.apiTokens != nil && strings.HasPrefix(token, a.apiTokenPrefix) {
+		principal, err := a.apiTokens(token)
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+		return principal, nil
+	}
+
+	claims, err := helper.ParseToken(token, false)
+	// tokens of OAuth clients acting on their own behalf have no user
+	if err != nil || claims.Id == "" || claims.Username == "" || a.isRevoked(claims) {
+		return nil, ErrInvalidToken
+	}
+
+	principal := &Principal{
+		Id:          claims.Id,
+		Username:    claims.Username,
+		Name:        claims.Name,
+		SessionId:   claims.SessionId,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		ClientId:    claims.ClientId,
+		Scopes:      strings.Fields(claims.Scope),
+	}
+	if claims.Actor != nil {
+		principal.ActorId = claims.Actor.Subject
+		principal.ActorUsername = claims.Actor.Username
+	}
+
+	return principal, nil
+}
+
+// Optional stores the principal when the request carries a valid access
+// token and lets every request through.
+func (a *Authenticator) Optional() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := a.Authenticate(c)
+		if err != nil {
+			return c.Next()
+		}
+
+		SetPrincipal(c, principal)
+
+		return next(c, principal)
+	}
+}
+
+// Required rejects requests without a valid access token and stores the
+// principal for the following handlers.
+func (a *Authenticator) Required() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := a.Authenticate(c)
+		if err != nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		SetPrincipal(c, principal)
+
+		return next(c, principal)
+	}
+}
+
+// NotImpersonated rejects impersonated requests, for account changes only
+// the user should make, such as their second factor or access tokens. It
+// must run after Authenticator.Required.
+func NotImpersonated() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if principal := GetPrincipal(c); principal != nil && principal.IsImpersonated() {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "not allowed while impersonating"})
+		}
+
+		return c.Next()
+	}
+}
+
+// next runs the following handlers. Requests made while impersonating are
+// logged with the actor once they have completed, so everything done on a
+// user's behalf can be traced back to the admin.
+func next(c *fiber.Ctx, principal *Principal) error {
+	if !principal.IsImpersonated() {
+		return c.Next()
+	}
+
+	err := c.Next()
+
+	logger.GetLogger().WithFields(logrus.Fields{
+		"impersonation": true,
+		"actor_id":      principal.ActorId,
+		"actor":         principal.ActorUsername,
+		"user_id":       principal.Id,
+		"method":        c.Method(),
+		"path":          c.Path(),
+		"status":        c.Response().StatusCode(),
+		"ip":            helper.ClientIP(c),
+	}).Info("impersonated request: " + principal.Username)
+
+	return err
+}
+
+func (a *Authenticator) extract(c *fiber.Ctx) string {
+	for _, source := range a.sources {
+		if token := source(c); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// isRevoked checks the token against the revocation store. Impersonation
+// tokens are also revoked with every token of the admin acting. Tokens are
+// rejected when the store cannot be reached.
+func (a *Authenticator) isRevoked(claims *helper.Claims) bool {
+	revoked, err := tokenstore.IsTokenRevoked(a.store, claims.ID, claims.Id, claims.SessionId, claims.IssuedAt.Time)
+	if err == nil && !revoked && claims.Actor != nil {
+		revoked, err = a.store.IsUserRevoked(claims.Actor.Subject, claims.IssuedAt.Time)
+	}
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("failed to check token revocation")
+		return true
+	}
+
+	return revoked
+}
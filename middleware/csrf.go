@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidCSRFToken = errors.New("missing or invalid csrf token")
+
+var bearerToken = FromAuthHeader("Bearer")
+
+// CSRFConfig configures the CSRF protection. Zero values fall back to the
+// defaults noted on each field.
+type CSRFConfig struct {
+	// Secret signs the tokens. When empty a random secret is generated, so
+	// tokens do not survive a restart and are not shared between instances.
+	Secret []byte
+	// CookieName holds the token for the SPA to read, "csrf_token" by
+	// default.
+	CookieName string
+	// HeaderName must repeat the token on mutating requests, "X-CSRF-Token"
+	// by default.
+	HeaderName string
+	// AuthCookies carry credentials. Requests sending none of them cannot
+	// be forged into acting on a session and are not checked. Defaults to
+	// access_token and refresh_token.
+	AuthCookies []string
+	// TTL is the lifetime of the cookie, 7 days by default.
+	TTL time.Duration
+}
+
+// CSRF implements signed double-submit tokens. The token is sent both in
+// a cookie and in a header; another site can make the browser send the
+// cookie but can neither read it nor set the header. The signature stops
+// tokens planted by a sibling domain from being accepted.
+type CSRF struct {
+	config CSRFConfig
+}
+
+func NewCSRF(config CSRFConfig) *CSRF {
+	if len(config.Secret) == 0 {
+		config.Secret = make([]byte, 32)
+		if _, err := rand.Read(config.Secret); err != nil {
+			panic(err)
+		}
+		logger.GetLogger().Warn("CSRF_SECRET is not set, using a random secret")
+	}
+	if config.CookieName == "" {
+		config.CookieName = "csrf_token"
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = "X-CSRF-Token"
+	}
+	if len(config.AuthCookies) == 0 {
+		config.AuthCookies = []string{"access_token", "refresh_token"}
+	}
+	if config.TTL == 0 {
+		config.TTL = 7 * 24 * time.Hour
+	}
+
+	return &CSRF{config: config}
+}
+
+// HeaderName returns the header the token has to be sent in.
+func (c *CSRF) HeaderName() string {
+	return c.config.HeaderName
+}
+
+// Issue generates a token and stores it in the CSRF cookie. The cookie is
+// readable by scripts, the token is not a credential on its own.
+func (c *CSRF) Issue(ctx *fiber.Ctx) (string, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(nonce)
+	token := encoded + "." + c.sign(encoded)
+
+	ctx.Cookie(&fiber.Cookie{
+		Name:     c.config.CookieName,
+		Value:    token,
+		Expires:  time.Now().Add(c.config.TTL),
+		Secure:   true,
+		SameSite: "Strict",
+	})
+
+	return token, nil
+}
+
+// Protect rejects mutating requests authenticated by cookie unless the
+// header repeats the signed token of the cookie. Requests with a Bearer
+// token are skipped: browsers do not attach it on their own and other
+// origins cannot set it without passing CORS. The authenticator must try
+// the Bearer header before the cookies, so such requests are never
+// authenticated by a cookie.
+func (c *CSRF) Protect() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		switch ctx.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+			return ctx.Next()
+		}
+
+		if bearerToken(ctx) != "" || !c.hasAuthCookie(ctx) {
+			return ctx.Next()
+		}
+
+		cookie := ctx.Cookies(c.config.CookieName)
+		header := ctx.Get(c.config.HeaderName)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 || !c.verify(cookie) {
+			return ctx.Status(http.StatusForbidden).JSON(fiber.Map{"error": ErrInvalidCSRFToken.Error()})
+		}
+
+		return ctx.Next()
+	}
+}
+
+func (c *CSRF) hasAuthCookie(ctx *fiber.Ctx) bool {
+	for _, name := range c.config.AuthCookies {
+		if ctx.Cookies(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CSRF) verify(token string) bool {
+	nonce, signature, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(c.sign(nonce)))
+}
+
+func (c *CSRF) sign(nonce string) string {
+	mac := hmac.New(sha256.New, c.config.Secret)
+	mac.Write([]byte(nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
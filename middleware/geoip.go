@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/fatihrizqon/go-fiber-service/geoip"
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/gofiber/fiber/v2"
+)
+
+const locationKey = "location"
+
+// Locate resolves the location of the client IP and stores it in the
+// request locals for GetLocation.
+func Locate(resolver geoip.Resolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(locationKey, resolver.Lookup(helper.ClientIP(c)))
+		return c.Next()
+	}
+}
+
+// GetLocation returns the location stored by Locate, or an empty location
+// when GeoIP is not configured.
+func GetLocation(c *fiber.Ctx) geoip.Location {
+	location, _ := c.Locals(locationKey).(geoip.Location)
+	return location
+}
@@ -1,5 +1,40 @@
 package middleware
 
-func Guest() {
-	panic("unimplemented")
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GuestConfig configures how Guest rejects authenticated requests.
+type GuestConfig struct {
+	// Status of the rejection, 409 Conflict when zero.
+	Status int
+	// RedirectTo, when set, is returned in the Location header and the body
+	// so clients know where an authenticated user should go instead.
+	RedirectTo string
+}
+
+// Guest only lets through requests without a valid access token, for routes
+// such as login and register. Expired or revoked tokens count as no token,
+// using the same checks as Authenticator.Required.
+func Guest(authenticator *Authenticator, config GuestConfig) fiber.Handler {
+	status := config.Status
+	if status == 0 {
+		status = http.StatusConflict
+	}
+
+	return func(c *fiber.Ctx) error {
+		if _, err := authenticator.Authenticate(c); err != nil {
+			return c.Next()
+		}
+
+		body := fiber.Map{"error": "already authenticated"}
+		if config.RedirectTo != "" {
+			c.Set(fiber.HeaderLocation, config.RedirectTo)
+			body["redirect"] = config.RedirectTo
+		}
+
+		return c.Status(status).JSON(body)
+	}
 }
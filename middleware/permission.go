@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission allows the request only if the authenticated user holds
+// every given permission. It must run after Authenticator.Required.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := GetPrincipal(c)
+		if principal == nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": ErrNoToken.Error()})
+		}
+
+		for _, permission := range permissions {
+			if !principal.HasPermission(permission) {
+				return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "missing permission: " + permission})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
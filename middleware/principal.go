@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const principalKey = "principal"
+
+// Principal is the authenticated caller of a request. ClientId and Scopes
+// are set when the token was issued to an OAuth client, ApiTokenId when the
+// caller used a personal access token instead of a session. ActorId and
+// ActorUsername name the admin behind an impersonation token.
+type Principal struct {
+	Id          string
+	Username    string
+	Name        string
+	SessionId   string
+	Roles       []string
+	Permissions []string
+	ClientId    string
+	Scopes      []string
+	ApiTokenId  string
+
+	ActorId       string
+	ActorUsername string
+}
+
+// UserId returns the id of the user as a UUID.
+func (p *Principal) UserId() (uuid.UUID, error) {
+	return uuid.Parse(p.Id)
+}
+
+// HasRole reports whether the principal holds the role.
+func (p *Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+// HasPermission reports whether the principal holds the permission. OAuth
+// clients also need a scope of the same name, whatever their token says.
+func (p *Principal) HasPermission(permission string) bool {
+	return contains(p.Permissions, permission) && p.HasScope(permission)
+}
+
+// IsApiToken reports whether the caller authenticated with a personal
+// access token.
+func (p *Principal) IsApiToken() bool {
+	return p.ApiTokenId != ""
+}
+
+// IsImpersonated reports whether an admin is acting as the user.
+func (p *Principal) IsImpersonated() bool {
+	return p.ActorId != ""
+}
+
+// HasScope reports whether the token was granted the scope. First-party
+// tokens carry no scopes and are not limited by them.
+func (p *Principal) HasScope(scope string) bool {
+	return p.ClientId == "" || contains(p.Scopes, scope)
+}
+
+// SetPrincipal stores the principal in the request locals.
+func SetPrincipal(c *fiber.Ctx, principal *Principal) {
+	c.Locals(principalKey, principal)
+}
+
+// GetPrincipal returns the principal stored by the authenticator, or nil on
+// routes that are not authenticated.
+func GetPrincipal(c *fiber.Ctx) *Principal {
+	principal, _ := c.Locals(principalKey).(*Principal)
+	return principal
+}
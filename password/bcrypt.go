@@ -0,0 +1,58 @@
+package password
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost is the cost used when none is configured.
+const DefaultBcryptCost = 14
+
+// BcryptMaxBytes is the longest password bcrypt accepts.
+const BcryptMaxBytes = 72
+
+type BcryptParams struct {
+	Cost int
+}
+
+// BcryptHasher hashes passwords with bcrypt. Passwords longer than
+// BcryptMaxBytes are rejected by bcrypt, set PolicyConfig.MaxBytes to
+// refuse them up front. Prefer argon2id for new deployments.
+type BcryptHasher struct {
+	params BcryptParams
+}
+
+func NewBcryptHasher(params BcryptParams) *BcryptHasher {
+	if params.Cost == 0 {
+		params.Cost = DefaultBcryptCost
+	}
+	return &BcryptHasher{params: params}
+}
+
+// Hash implements Hasher.
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.params.Cost)
+	return string(hashed), err
+}
+
+// Verify implements Hasher.
+func (h *BcryptHasher) Verify(password, encoded string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
+
+// NeedsRehash implements Hasher.
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.params.Cost
+}
+
+// Recognizes reports whether encoded is a bcrypt hash.
+func (h *BcryptHasher) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
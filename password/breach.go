@@ -0,0 +1,154 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BreachCorpus looks passwords up in a local copy of the Have I Been Pwned
+// password list, so no password or hash leaves the service. Path is either
+// a directory of range files, one per 5 character SHA-1 prefix as served
+// by the k-anonymity API (named ABCDE or ABCDE.txt, lines SUFFIX:COUNT), or
+// a single file of HASH:COUNT lines ordered by hash.
+type BreachCorpus struct {
+	path string
+	dir  bool
+	// minCount is the number of occurrences from which a password counts
+	// as breached
+	minCount int
+}
+
+// NewBreachCorpus opens the corpus at path. minCount defaults to 1.
+func NewBreachCorpus(path string, minCount int) (*BreachCorpus, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if minCount <= 0 {
+		minCount = 1
+	}
+	return &BreachCorpus{path: path, dir: info.IsDir(), minCount: minCount}, nil
+}
+
+// Count returns how often the password appears in the corpus, or 0 when
+// it appears less than the minimum count.
+func (c *BreachCorpus) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	var count int
+	var err error
+	if c.dir {
+		count, err = c.countInRange(hash)
+	} else {
+		count, err = c.countInOrderedFile(hash)
+	}
+	if err != nil || count < c.minCount {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (c *BreachCorpus) countInRange(hash string) (int, error) {
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(c.path, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(filepath.Join(c.path, prefix))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if count, ok := matchLine(scanner.Text(), suffix); ok {
+			return count, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// countInOrderedFile binary searches the file by byte offset. The line
+// found after an offset only grows with the offset, so the search ends at
+// the first line not before hash.
+func (c *BreachCorpus) countInOrderedFile(hash string) (int, error) {
+	file, err := os.Open(c.path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	low, high := int64(0), info.Size()
+	for low < high {
+		mid := (low + high) / 2
+		line, err := lineAfter(file, mid)
+		if err != nil {
+			return 0, err
+		}
+		if line == "" || strings.ToUpper(line) >= hash {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	line, err := lineAfter(file, low)
+	if err != nil {
+		return 0, err
+	}
+	count, _ := matchLine(line, hash)
+	return count, nil
+}
+
+// lineAfter returns the first complete line starting after offset, or the
+// first line of the file for offset 0.
+func lineAfter(file *os.File, offset int64) (string, error) {
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	reader := bufio.NewReader(file)
+	if offset > 0 {
+		if _, err := reader.ReadString('\n'); err != nil {
+			if err == io.EOF {
+				return "", nil
+			}
+			return "", err
+		}
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func matchLine(line, hash string) (int, bool) {
+	value, count, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found || !strings.EqualFold(value, hash) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,103 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMismatch    = errors.New("password does not match")
+	ErrUnknownHash = errors.New("unknown password hash format")
+)
+
+// Hasher hashes passwords into self-describing strings, so the algorithm
+// and its parameters can change without invalidating stored hashes.
+type Hasher interface {
+	// Hash returns the encoded hash of the password.
+	Hash(password string) (string, error)
+	// Verify checks the password against an encoded hash. It returns
+	// ErrMismatch when the password is wrong.
+	Verify(password, encoded string) error
+	// NeedsRehash reports whether the hash was made with another algorithm
+	// or other parameters than the hasher would use now.
+	NeedsRehash(encoded string) bool
+}
+
+// ContextHasher is a Hasher that can give up when a context is done, such
+// as Pool.
+type ContextHasher interface {
+	Hasher
+	HashContext(ctx context.Context, password string) (string, error)
+	VerifyContext(ctx context.Context, password, encoded string) error
+}
+
+// HashContext hashes the password with h, passing ctx on when h is a
+// ContextHasher.
+func HashContext(ctx context.Context, h Hasher, password string) (string, error) {
+	if h, ok := h.(ContextHasher); ok {
+		return h.HashContext(ctx, password)
+	}
+	return h.Hash(password)
+}
+
+// VerifyContext verifies the password with h, passing ctx on when h is a
+// ContextHasher.
+func VerifyContext(ctx context.Context, h Hasher, password, encoded string) error {
+	if h, ok := h.(ContextHasher); ok {
+		return h.VerifyContext(ctx, password, encoded)
+	}
+	return h.Verify(password, encoded)
+}
+
+// algorithm is a Hasher that can tell its own hashes apart from others.
+type algorithm interface {
+	Hasher
+	Recognizes(encoded string) bool
+}
+
+// Config selects the algorithm new hashes are made with. Zero parameters
+// fall back to the defaults of the algorithm.
+type Config struct {
+	Algorithm string
+	Bcrypt    BcryptParams
+	Argon2id  Argon2idParams
+}
+
+// New returns a Hasher that hashes with the configured algorithm, argon2id
+// when none is set, and verifies hashes of every supported algorithm.
+func New(config Config) (Hasher, error) {
+	argon := NewArgon2idHasher(config.Argon2id)
+	bcrypt := NewBcryptHasher(config.Bcrypt)
+
+	switch config.Algorithm {
+	case "", "argon2id":
+		return &hasher{current: argon, known: []algorithm{argon, bcrypt}}, nil
+	case "bcrypt":
+		return &hasher{current: bcrypt, known: []algorithm{bcrypt, argon}}, nil
+	default:
+		return nil, fmt.Errorf("unknown password hasher: %s", config.Algorithm)
+	}
+}
+
+type hasher struct {
+	current algorithm
+	known   []algorithm
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *hasher) Verify(password, encoded string) error {
+	for _, a := range h.known {
+		if a.Recognizes(encoded) {
+			return a.Verify(password, encoded)
+		}
+	}
+	return ErrUnknownHash
+}
+
+func (h *hasher) NeedsRehash(encoded string) bool {
+	return !h.current.Recognizes(encoded) || h.current.NeedsRehash(encoded)
+}
@@ -0,0 +1,213 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrPolicyViolated = errors.New("password does not meet the password policy")
+
+// Rule names reported in violations.
+const (
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RuleUpper     = "uppercase"
+	RuleLower     = "lowercase"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleContext   = "context"
+	RuleHistory   = "history"
+	RuleBreached  = "breached"
+)
+
+// Violation is one broken rule of the policy.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PolicyError lists every rule a password breaks. It wraps
+// ErrPolicyViolated.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	messages := []string{}
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return ErrPolicyViolated.Error() + ": " + strings.Join(messages, ", ")
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyViolated
+}
+
+// PolicyConfig configures the password rules. MinLength defaults to 8 and
+// MaxLength to 128, the other rules are off unless enabled.
+type PolicyConfig struct {
+	MinLength int
+	MaxLength int
+	// MaxBytes caps the UTF-8 length of passwords for hashers with a
+	// limit of their own, such as BcryptMaxBytes. 0 means no limit.
+	MaxBytes      int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DenyContext rejects passwords containing the username, the local
+	// part of the email or a part of the name.
+	DenyContext bool
+	// HistorySize is the number of previous passwords that may not be
+	// reused, 0 disables the rule.
+	HistorySize int
+	// Breaches, when set, rejects passwords found in a breach corpus.
+	Breaches *BreachCorpus
+}
+
+// PolicyContext describes the account a password is checked for. History
+// holds the hashes of its most recent passwords.
+type PolicyContext struct {
+	Username string
+	Email    string
+	Name     string
+	History  []string
+}
+
+// Policy checks new passwords against the configured rules.
+type Policy struct {
+	config PolicyConfig
+	hasher Hasher
+}
+
+// NewPolicy returns a policy. hasher verifies passwords against the
+// history, it may be nil when the history rule is off.
+func NewPolicy(config PolicyConfig, hasher Hasher) *Policy {
+	if config.MinLength <= 0 {
+		config.MinLength = 8
+	}
+	if config.MaxLength <= 0 {
+		config.MaxLength = 128
+	}
+	return &Policy{config: config, hasher: hasher}
+}
+
+// HistorySize returns the number of previous passwords the policy checks.
+func (p *Policy) HistorySize() int {
+	return p.config.HistorySize
+}
+
+// Check returns a *PolicyError listing every broken rule, or nil. Other
+// errors come from reading the breach corpus or verifying the history.
+func (p *Policy) Check(password string, ctx PolicyContext) error {
+	return p.CheckContext(context.Background(), password, ctx)
+}
+
+// CheckContext is Check giving up on the history when ctx is done while
+// waiting for the hasher.
+func (p *Policy) CheckContext(ctx context.Context, password string, subject PolicyContext) error {
+	violations := []Violation{}
+	add := func(rule, format string, args ...any) {
+		violations = append(violations, Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		add(RuleMinLength, "must be at least %d characters long", p.config.MinLength)
+	}
+	if length > p.config.MaxLength {
+		add(RuleMaxLength, "must be at most %d characters long", p.config.MaxLength)
+	} else if p.config.MaxBytes > 0 && len(password) > p.config.MaxBytes {
+		add(RuleMaxLength, "must be at most %d bytes long", p.config.MaxBytes)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.config.RequireUpper && !upper {
+		add(RuleUpper, "must contain an uppercase letter")
+	}
+	if p.config.RequireLower && !lower {
+		add(RuleLower, "must contain a lowercase letter")
+	}
+	if p.config.RequireDigit && !digit {
+		add(RuleDigit, "must contain a digit")
+	}
+	if p.config.RequireSymbol && !symbol {
+		add(RuleSymbol, "must contain a symbol")
+	}
+
+	if p.config.DenyContext {
+		if word, found := containsContextWord(password, subject); found {
+			add(RuleContext, "must not contain %q", word)
+		}
+	}
+
+	if p.config.HistorySize > 0 && p.hasher != nil {
+		history := subject.History
+		if len(history) > p.config.HistorySize {
+			history = history[:p.config.HistorySize]
+		}
+		for _, hashed := range history {
+			err := VerifyContext(ctx, p.hasher, password, hashed)
+			if err == nil {
+				add(RuleHistory, "must not be one of your last %d passwords", p.config.HistorySize)
+				break
+			}
+			if !errors.Is(err, ErrMismatch) && !errors.Is(err, ErrUnknownHash) {
+				return err
+			}
+		}
+	}
+
+	if p.config.Breaches != nil {
+		count, err := p.config.Breaches.Count(password)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			add(RuleBreached, "has appeared in a data breach and must not be used")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// contextWordMinLength skips short name parts such as initials, which
+// would reject far too many passwords.
+const contextWordMinLength = 3
+
+func containsContextWord(password string, ctx PolicyContext) (string, bool) {
+	words := []string{ctx.Username}
+	if local, _, found := strings.Cut(ctx.Email, "@"); found {
+		words = append(words, local)
+	}
+	words = append(words, strings.Fields(ctx.Name)...)
+
+	lowered := strings.ToLower(password)
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if utf8.RuneCountInString(word) >= contextWordMinLength && strings.Contains(lowered, word) {
+			return word, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,188 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+var ErrPoolSaturated = errors.New("password hashing is busy, try again later")
+
+// SaturatedError is returned when the pool cannot take more work. It wraps
+// ErrPoolSaturated.
+type SaturatedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *SaturatedError) Error() string {
+	return ErrPoolSaturated.Error()
+}
+
+func (e *SaturatedError) Unwrap() error {
+	return ErrPoolSaturated
+}
+
+// PoolConfig bounds the pool. Zero values use the defaults.
+type PoolConfig struct {
+	// Workers is the number of hashes computed at the same time, the
+	// number of CPUs by default.
+	Workers int
+	// QueueSize is the number of callers allowed to wait for a worker,
+	// four per worker by default. Further callers fail immediately.
+	QueueSize int
+	// QueueTimeout is how long a caller waits for a worker, 5s by default.
+	QueueTimeout time.Duration
+	// RetryAfter is suggested to callers that were turned away, 1s by
+	// default.
+	RetryAfter time.Duration
+}
+
+// PoolStats is a snapshot of the pool, published through expvar.
+type PoolStats struct {
+	Workers   int    `json:"workers"`
+	QueueSize int    `json:"queue_size"`
+	Active    int64  `json:"active"`
+	Queued    int64  `json:"queued"`
+	Completed uint64 `json:"completed"`
+	Rejected  uint64 `json:"rejected"`
+}
+
+// Pool runs a Hasher with bounded concurrency, so a burst of logins cannot
+// occupy every CPU and slow down the rest of the service. Work still runs
+// on the calling goroutine, the pool only limits how many run at once and
+// how many may wait.
+type Pool struct {
+	hasher Hasher
+	config PoolConfig
+	slots  chan struct{}
+
+	active    atomic.Int64
+	queued    atomic.Int64
+	completed atomic.Uint64
+	rejected  atomic.Uint64
+}
+
+func NewPool(hasher Hasher, config PoolConfig) *Pool {
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = config.Workers * 4
+	}
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = 5 * time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+
+	return &Pool{
+		hasher: hasher,
+		config: config,
+		slots:  make(chan struct{}, config.Workers),
+	}
+}
+
+// Hash implements Hasher.
+func (p *Pool) Hash(password string) (string, error) {
+	return p.HashContext(context.Background(), password)
+}
+
+// HashContext is Hash giving up when ctx is done while waiting for a
+// worker.
+func (p *Pool) HashContext(ctx context.Context, password string) (string, error) {
+	var hashed string
+	err := p.run(ctx, func() (err error) {
+		hashed, err = p.hasher.Hash(password)
+		return err
+	})
+	return hashed, err
+}
+
+// Verify implements Hasher.
+func (p *Pool) Verify(password, encoded string) error {
+	return p.VerifyContext(context.Background(), password, encoded)
+}
+
+// VerifyContext is Verify giving up when ctx is done while waiting for a
+// worker.
+func (p *Pool) VerifyContext(ctx context.Context, password, encoded string) error {
+	return p.run(ctx, func() error {
+		return p.hasher.Verify(password, encoded)
+	})
+}
+
+// NeedsRehash implements Hasher. It only parses the hash and does not go
+// through the pool.
+func (p *Pool) NeedsRehash(encoded string) bool {
+	return p.hasher.NeedsRehash(encoded)
+}
+
+// Stats returns the current state of the pool.
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Workers:   p.config.Workers,
+		QueueSize: p.config.QueueSize,
+		Active:    p.active.Load(),
+		Queued:    p.queued.Load(),
+		Completed: p.completed.Load(),
+		Rejected:  p.rejected.Load(),
+	}
+}
+
+// Publish exports the pool stats as an expvar variable. It panics if the
+// name is already in use, like expvar.Publish.
+func (p *Pool) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return p.Stats()
+	}))
+}
+
+func (p *Pool) run(ctx context.Context, work func() error) error {
+	if err := p.acquire(ctx); err != nil {
+		return err
+	}
+
+	p.active.Add(1)
+	defer func() {
+		p.active.Add(-1)
+		p.completed.Add(1)
+		<-p.slots
+	}()
+
+	return work()
+}
+
+func (p *Pool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if p.queued.Add(1) > int64(p.config.QueueSize) {
+		p.queued.Add(-1)
+		return p.reject()
+	}
+	defer p.queued.Add(-1)
+
+	timer := time.NewTimer(p.config.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return p.reject()
+	}
+}
+
+func (p *Pool) reject() error {
+	p.rejected.Add(1)
+	return &SaturatedError{RetryAfter: p.config.RetryAfter}
+}
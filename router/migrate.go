@@ -6,5 +6,20 @@ import (
 )
 
 func Migrate(db *gorm.DB) {
-	db.AutoMigrate(&entity.User{})
+	db.AutoMigrate(
+		&entity.User{},
+		&entity.PasswordReset{},
+		&entity.RevokedToken{},
+		&entity.Session{},
+		&entity.Permission{},
+		&entity.Role{},
+		&entity.MfaRecoveryCode{},
+		&entity.LoginThrottle{},
+		&entity.SecurityEvent{},
+		&entity.OAuthClient{},
+		&entity.OAuthAuthorizationCode{},
+		&entity.ApiToken{},
+		&entity.PasswordHistory{},
+		&entity.LoginEvent{},
+	)
 }
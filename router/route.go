@@ -4,38 +4,222 @@ import (
 	"log"
 
 	"github.com/fatihrizqon/go-fiber-service/config"
+	"github.com/fatihrizqon/go-fiber-service/geoip"
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
 	"github.com/fatihrizqon/go-fiber-service/internal/handler"
 	"github.com/fatihrizqon/go-fiber-service/internal/repository"
 	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"github.com/fatihrizqon/go-fiber-service/mailer"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/fatihrizqon/go-fiber-service/password"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
 )
 
-func NewRouter(app *fiber.App) {
-	env, err := config.DotEnv()
-
-	if err != nil {
-		log.Fatalln("could not load environment variables", err)
-	}
-
+func NewRouter(app *fiber.App, env config.Environment) {
 	// establish database connection
 	db := config.ConnectDatabase(&env)
 	validate := validator.New()
 
 	// run auto migrate
 	Migrate(db)
+	Seed(db, env.AdminEmail)
+
+	// Register the Mailer
+	mail, err := mailer.New(mailer.Config{
+		Driver:       env.MailDriver,
+		From:         env.MailFrom,
+		Path:         env.MailPath,
+		SMTPHost:     env.MailSMTPHost,
+		SMTPPort:     env.MailSMTPPort,
+		SMTPUsername: env.MailSMTPUsername,
+		SMTPPassword: env.MailSMTPPassword,
+	})
+	if err != nil {
+		log.Fatalln("could not configure mailer", err)
+	}
+	accountMailer := service.NewAccountMailer(mail, env.FrontendEndpoint)
+
+	// Register the Trusted Proxies, which every handler relies on through
+	// helper.ClientIP to know the address of the client
+	proxies, err := helper.NewTrustedProxies(env.TrustedProxies, env.ClientIPHeader)
+	if err != nil {
+		log.Fatalln("could not configure trusted proxies", err)
+	}
+	helper.UseTrustedProxies(proxies)
+
+	// Register the Token Secrets and Lifetimes
+	helper.UseTokenConfig(helper.TokenConfig{
+		AccessSecret:         []byte(env.JWTSecret),
+		RefreshSecret:        []byte(env.JWTRefreshSecret),
+		VerificationSecret:   []byte(env.JWTVerificationSecret),
+		MfaSecret:            []byte(env.JWTMfaSecret),
+		MagicLinkSecret:      []byte(env.JWTMagicLinkSecret),
+		AccessTokenTTL:       env.AccessTokenTTL,
+		RefreshTokenTTL:      env.RefreshTokenTTL,
+		EmailVerificationTTL: env.EmailVerificationTTL,
+		MfaChallengeTTL:      env.MfaChallengeTTL,
+		MagicLinkTTL:         env.MagicLinkTTL,
+		ImpersonationTTL:     env.ImpersonationTTL,
+	})
+
+	// Register the Access Token Keys
+	if env.JWTKeysDir != "" {
+		keys, err := helper.LoadKeySet(env.JWTKeysDir, env.JWTActiveKeyId)
+		if err != nil {
+			log.Fatalln("could not load signing keys", err)
+		}
+		helper.UseAccessKeySet(keys)
+	}
+
+	// Register the Password Hasher
+	hasher, err := password.New(password.Config{
+		Algorithm: env.PasswordHasher,
+		Bcrypt:    password.BcryptParams{Cost: env.BcryptCost},
+		Argon2id: password.Argon2idParams{
+			Memory:      env.Argon2Memory,
+			Iterations:  env.Argon2Iterations,
+			Parallelism: env.Argon2Parallelism,
+		},
+	})
+	if err != nil {
+		log.Fatalln("could not configure password hasher", err)
+	}
+	hasherPool := password.NewPool(hasher, password.PoolConfig{
+		Workers:      env.PasswordWorkers,
+		QueueSize:    env.PasswordQueueSize,
+		QueueTimeout: env.PasswordQueueTimeout,
+	})
+	hasherPool.Publish("password_pool")
+
+	// Register the Password Policy
+	var breaches *password.BreachCorpus
+	if env.PasswordBreachCorpus != "" {
+		breaches, err = password.NewBreachCorpus(env.PasswordBreachCorpus, env.PasswordBreachMinCount)
+		if err != nil {
+			log.Fatalln("could not open breached password corpus", err)
+		}
+	}
+	policyConfig := password.PolicyConfig{
+		MinLength:     env.PasswordMinLength,
+		MaxLength:     env.PasswordMaxLength,
+		RequireUpper:  env.PasswordRequireUpper,
+		RequireLower:  env.PasswordRequireLower,
+		RequireDigit:  env.PasswordRequireDigit,
+		RequireSymbol: env.PasswordRequireSymbol,
+		DenyContext:   env.PasswordDenyContext,
+		HistorySize:   env.PasswordHistory,
+		Breaches:      breaches,
+	}
+	if env.PasswordHasher == "bcrypt" {
+		policyConfig.MaxBytes = password.BcryptMaxBytes
+	}
+	passwordPolicy := password.NewPolicy(policyConfig, hasherPool)
+
+	// Register the GeoIP Resolver, locating clients in logs, sessions and
+	// the login history
+	if env.GeoIPDatabase != "" {
+		geo, err := geoip.Open(geoip.Config{
+			Path:           env.GeoIPDatabase,
+			CacheSize:      env.GeoIPCacheSize,
+			ReloadInterval: env.GeoIPReloadInterval,
+		})
+		if err != nil {
+			log.Fatalln("could not open geoip database", err)
+		}
+		logger.SetLocator(func(ip string) string {
+			return geo.Lookup(ip).String()
+		})
+		app.Use(middleware.Locate(geo))
+	}
+
+	// Register the Token Store
+	tokenStore, err := tokenstore.New(tokenstore.Config{
+		Driver:   env.TokenStoreDriver,
+		Db:       db,
+		RedisURL: env.RedisURL,
+	})
+	if err != nil {
+		log.Fatalln("could not configure token store", err)
+	}
 
 	// Register the Repositories
 	userRepository := repository.NewUserRepository(db)
 	authRepository := repository.NewAuthRepository(db)
+	passwordResetRepository := repository.NewPasswordResetRepository(db)
+	sessionRepository := repository.NewSessionRepository(db)
+	roleRepository := repository.NewRoleRepository(db)
+	mfaRepository := repository.NewMfaRepository(db)
+	loginThrottleRepository := repository.NewLoginThrottleRepository(db)
+	securityEventRepository := repository.NewSecurityEventRepository(db)
+	oauthRepository := repository.NewOAuthRepository(db)
+	apiTokenRepository := repository.NewApiTokenRepository(db)
+	passwordHistoryRepository := repository.NewPasswordHistoryRepository(db)
+	loginEventRepository := repository.NewLoginEventRepository(db)
 
 	// Register the Services
-	userService := service.NewUserService(userRepository, validate)
-	authService := service.NewAuthService(authRepository, validate)
+	passwordPolicyService := service.NewPasswordPolicyService(passwordHistoryRepository, passwordPolicy)
+	userService := service.NewUserService(userRepository, accountMailer, hasherPool, passwordPolicyService, validate)
+	sessionService := service.NewSessionService(sessionRepository, tokenStore)
+	securityEventService := service.NewSecurityEventService(securityEventRepository)
+	loginEventService := service.NewLoginEventService(loginEventRepository, accountMailer)
+	loginThrottleService := service.NewLoginThrottleService(loginThrottleRepository, authRepository, securityEventService, service.ThrottleConfig{
+		FreeAttempts:         env.LoginThrottleFreeAttempts,
+		BaseDelay:            env.LoginThrottleBaseDelay,
+		MaxDelay:             env.LoginThrottleMaxDelay,
+		MaxFailures:          env.LoginThrottleMaxFailures,
+		LockoutDuration:      env.LoginLockoutDuration,
+		Window:               env.LoginThrottleWindow,
+		PruneInterval:        env.LoginThrottlePruneInterval,
+		MaxChallengeFailures: env.MfaChallengeMaxFailures,
+	})
+	tokenService := service.NewTokenService(authRepository, sessionService, securityEventService, tokenStore)
+	roleService := service.NewRoleService(roleRepository, validate)
+	mfaService := service.NewMfaService(authRepository, mfaRepository, loginThrottleService, loginEventService, tokenStore, hasherPool, env.MfaIssuer, validate)
+	authService := service.NewAuthService(authRepository, passwordResetRepository, sessionService, loginThrottleService, loginEventService, accountMailer, hasherPool, passwordPolicyService, service.AuthConfig{
+		RequireVerifiedEmail: env.RequireVerifiedEmail,
+	}, validate)
+	oauthService := service.NewOAuthService(oauthRepository, authRepository, sessionRepository, tokenService, service.OAuthConfig{
+		Issuer:   env.OIDCIssuer,
+		LoginURL: env.FrontendEndpoint + "/login",
+	}, validate)
+	apiTokenService := service.NewApiTokenService(apiTokenRepository, authRepository, validate)
+	impersonationService := service.NewImpersonationService(authRepository, sessionRepository, securityEventService, tokenStore)
+	magicLinkService := service.NewMagicLinkService(authRepository, loginThrottleService, loginEventService, tokenStore, accountMailer, validate)
 
 	// Register the Handlers
 	userHandler := handler.NewUserHandler(userService)
-	authHandler := handler.NewAuthHandler(authService)
+	authHandler := handler.NewAuthHandler(authService, tokenService, mfaService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	mfaHandler := handler.NewMfaHandler(mfaService, tokenService)
+	loginThrottleHandler := handler.NewLoginThrottleHandler(loginThrottleService)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+	wellKnownHandler := handler.NewWellKnownHandler(oauthService)
+	apiTokenHandler := handler.NewApiTokenHandler(apiTokenService)
+	magicLinkHandler := handler.NewMagicLinkHandler(magicLinkService, tokenService, mfaService)
+	csrf := middleware.NewCSRF(middleware.CSRFConfig{Secret: []byte(env.CSRFSecret)})
+	csrfHandler := handler.NewCSRFHandler(csrf)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService)
+	loginEventHandler := handler.NewLoginEventHandler(loginEventService)
+
+	// Register the Middlewares
+	authenticator := middleware.NewAuthenticator(tokenStore,
+		middleware.FromAuthHeader("Bearer"),
+		middleware.FromCookie("access_token"),
+		middleware.FromQuery("access_token"),
+	).WithApiTokens(service.ApiTokenPrefix, handler.ApiTokenVerifier(apiTokenService))
+	authenticated := authenticator.Required()
+	guest := middleware.Guest(authenticator, middleware.GuestConfig{})
+	notImpersonated := middleware.NotImpersonated()
+
+	// cookie authenticated mutations under /api/v1 need the CSRF header,
+	// this includes /auth/refresh and /auth/logout
+	app.Use("/api/v1", csrf.Protect())
 
 	app.Get("/api/v1", func(c *fiber.Ctx) error {
 		return c.Status(200).JSON(fiber.Map{
@@ -44,30 +228,67 @@ func NewRouter(app *fiber.App) {
 		})
 	})
 
-	// app.Post("/api/v1/auth/register", authHandler.Register) // unimplemented
-	app.Post("/api/v1/auth/login", authHandler.Login)
+	app.Get("/debug/vars", authenticated, middleware.RequirePermission(entity.PermissionMetricsRead), expvar.New())
+
+	app.Get("/.well-known/jwks.json", wellKnownHandler.JWKS)
+	app.Get("/.well-known/openid-configuration", wellKnownHandler.OpenIDConfiguration)
+
+	app.Get("/oauth/authorize", authenticator.Optional(), oauthHandler.Authorize)
+	app.Post("/oauth/token", oauthHandler.Token)
+	app.Get("/oauth/userinfo", authenticated, oauthHandler.UserInfo)
+	app.Post("/oauth/introspect", oauthHandler.Introspect)
+	app.Post("/oauth/revoke", oauthHandler.Revoke)
+
+	app.Get("/api/v1/auth/csrf", csrfHandler.Token)
+	app.Post("/api/v1/auth/register", guest, authHandler.Register)
+	app.Post("/api/v1/auth/login", guest, authHandler.Login)
+	app.Post("/api/v1/auth/magic-link", guest, magicLinkHandler.Request)
+	app.Post("/api/v1/auth/magic-link/consume", guest, magicLinkHandler.Consume)
+	app.Post("/api/v1/auth/verify-email", authHandler.VerifyEmail)
+	app.Post("/api/v1/auth/verify-email/resend", authHandler.ResendVerification)
+	app.Post("/api/v1/auth/forgot-password", guest, authHandler.ForgotPassword)
+	app.Post("/api/v1/auth/reset-password", authHandler.ResetPassword)
 	app.Post("/api/v1/auth/refresh", authHandler.Refresh)
 	app.Post("/api/v1/auth/logout", authHandler.Logout)
-	app.Get("/api/v1/auth/me", authHandler.Me)
+	app.Get("/api/v1/auth/me", authenticated, authHandler.Me)
+	app.Post("/api/v1/auth/logout-all", authenticated, notImpersonated, sessionHandler.RevokeAll)
+	app.Get("/api/v1/auth/sessions", authenticated, sessionHandler.FindAll)
+	app.Get("/api/v1/auth/login-history", authenticated, loginEventHandler.FindAll)
+	app.Delete("/api/v1/auth/sessions/:id", authenticated, sessionHandler.Revoke)
+	app.Post("/api/v1/auth/mfa/verify", guest, mfaHandler.Verify)
+	app.Post("/api/v1/auth/mfa/enroll", authenticated, notImpersonated, mfaHandler.Enroll)
+	app.Post("/api/v1/auth/mfa/confirm", authenticated, notImpersonated, mfaHandler.Confirm)
+	app.Post("/api/v1/auth/mfa/disable", authenticated, notImpersonated, mfaHandler.Disable)
+	app.Get("/api/v1/auth/tokens", authenticated, apiTokenHandler.FindAll)
+	app.Post("/api/v1/auth/tokens", authenticated, notImpersonated, apiTokenHandler.Create)
+	app.Get("/api/v1/auth/tokens/:id", authenticated, apiTokenHandler.FindById)
+	app.Delete("/api/v1/auth/tokens/:id", authenticated, notImpersonated, apiTokenHandler.Delete)
+	app.Post("/api/v1/auth/impersonate/:id", authenticated, middleware.RequirePermission(entity.PermissionUsersImpersonate), impersonationHandler.Start)
+	app.Delete("/api/v1/auth/impersonate", authenticated, impersonationHandler.End)
 
 	/*
-	 * Wrapping in JWT Middleware
+	 * Wrapping in JWT Middleware, public routes must be registered above
 	 */
-	// api := app.Group("/api/v1", middleware.JWT)
-	api := app.Group("/api/v1")
-
-	api.Post("/users", userHandler.Create)
-	api.Get("/users", userHandler.FindAll)
-	api.Get("/users/:id", userHandler.FindById)
-	api.Put("/users/:id", userHandler.Update)
-	api.Delete("/users/:id", userHandler.Delete)
-
-	// api.Post("/logout", func(c *fiber.Ctx) error {
-	// 	token := c.Get("Authorization")
-	// 	helper.BlacklistToken(token)
-	// 	return c.Status(200).JSON(fiber.Map{
-	// 		"status":  200,
-	// 		"message": "you are unauthenticated",
-	// 	})
-	// })
+	api := app.Group("/api/v1", authenticated)
+
+	api.Post("/users", middleware.RequirePermission(entity.PermissionUsersCreate), userHandler.Create)
+	api.Get("/users", middleware.RequirePermission(entity.PermissionUsersRead), userHandler.FindAll)
+	api.Get("/users/:id", middleware.RequirePermission(entity.PermissionUsersRead), userHandler.FindById)
+	api.Put("/users/:id", middleware.RequirePermission(entity.PermissionUsersUpdate), userHandler.Update)
+	api.Delete("/users/:id", middleware.RequirePermission(entity.PermissionUsersDelete), userHandler.Delete)
+	api.Get("/users/:id/sessions", middleware.RequirePermission(entity.PermissionUsersRead), sessionHandler.FindAllByUser)
+	api.Delete("/users/:id/sessions", middleware.RequirePermission(entity.PermissionUsersUpdate), sessionHandler.RevokeAllByUser)
+	api.Put("/users/:id/roles", middleware.RequirePermission(entity.PermissionRolesManage), roleHandler.AssignToUser)
+	api.Post("/users/:id/unlock", middleware.RequirePermission(entity.PermissionUsersUpdate), loginThrottleHandler.Unlock)
+
+	api.Get("/roles", middleware.RequirePermission(entity.PermissionRolesRead), roleHandler.FindAll)
+	api.Post("/roles", middleware.RequirePermission(entity.PermissionRolesManage), roleHandler.Create)
+	api.Get("/roles/:id", middleware.RequirePermission(entity.PermissionRolesRead), roleHandler.FindById)
+	api.Put("/roles/:id", middleware.RequirePermission(entity.PermissionRolesManage), roleHandler.Update)
+	api.Delete("/roles/:id", middleware.RequirePermission(entity.PermissionRolesManage), roleHandler.Delete)
+	api.Get("/permissions", middleware.RequirePermission(entity.PermissionRolesRead), roleHandler.FindAllPermissions)
+
+	api.Get("/oauth/clients", middleware.RequirePermission(entity.PermissionClientsManage), oauthHandler.FindAllClients)
+	api.Post("/oauth/clients", middleware.RequirePermission(entity.PermissionClientsManage), oauthHandler.CreateClient)
+	api.Delete("/oauth/clients/:id", middleware.RequirePermission(entity.PermissionClientsManage), oauthHandler.DeleteClient)
 }
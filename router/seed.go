@@ -0,0 +1,54 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"gorm.io/gorm"
+)
+
+// Seed makes sure the default permissions and the admin role exist. The
+// admin role always holds every default permission. If adminEmail belongs
+// to a registered user, that user is granted the admin role.
+func Seed(db *gorm.DB, adminEmail string) {
+	log := logger.GetLogger()
+
+	permissions := []entity.Permission{}
+	for _, permission := range entity.DefaultPermissions {
+		if err := db.Where(entity.Permission{Name: permission.Name}).
+			Attrs(entity.Permission{Description: permission.Description}).
+			FirstOrCreate(&permission).Error; err != nil {
+			log.WithError(err).Error("failed to seed permission: " + permission.Name)
+			return
+		}
+		permissions = append(permissions, permission)
+	}
+
+	admin := entity.Role{Name: entity.RoleAdmin}
+	if err := db.Where(entity.Role{Name: entity.RoleAdmin}).
+		Attrs(entity.Role{Description: "Full access to the service"}).
+		FirstOrCreate(&admin).Error; err != nil {
+		log.WithError(err).Error("failed to seed admin role")
+		return
+	}
+
+	if err := db.Model(&admin).Association("Permissions").Append(permissions); err != nil {
+		log.WithError(err).Error("failed to grant permissions to admin role")
+		return
+	}
+
+	if adminEmail == "" {
+		return
+	}
+
+	var user entity.User
+	if err := db.Where("email = ?", strings.ToLower(adminEmail)).First(&user).Error; err != nil {
+		log.Warn("admin user not found: " + adminEmail)
+		return
+	}
+
+	if err := db.Model(&user).Association("Roles").Append(&admin); err != nil {
+		log.WithError(err).Error("failed to grant admin role: " + adminEmail)
+	}
+}
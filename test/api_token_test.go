@@ -0,0 +1,170 @@
+package test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/handler"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type MemoryApiTokenRepository struct {
+	tokens map[uuid.UUID]entity.ApiToken
+}
+
+func (m *MemoryApiTokenRepository) Create(token entity.ApiToken) (entity.ApiToken, error) {
+	token.Id = uuid.New()
+	token.CreatedAt = time.Now()
+	m.tokens[token.Id] = token
+	return token, nil
+}
+
+func (m *MemoryApiTokenRepository) FindAllByUserId(userId uuid.UUID) ([]entity.ApiToken, error) {
+	tokens := []entity.ApiToken{}
+	for _, token := range m.tokens {
+		if token.UserId == userId {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *MemoryApiTokenRepository) FindById(id uuid.UUID) (entity.ApiToken, error) {
+	token, ok := m.tokens[id]
+	if !ok {
+		return token, gorm.ErrRecordNotFound
+	}
+	return token, nil
+}
+
+func (m *MemoryApiTokenRepository) FindByHash(tokenHash string) (entity.ApiToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return entity.ApiToken{}, gorm.ErrRecordNotFound
+}
+
+func (m *MemoryApiTokenRepository) Touch(id uuid.UUID, usedAt time.Time) error {
+	token := m.tokens[id]
+	token.LastUsedAt = &usedAt
+	m.tokens[id] = token
+	return nil
+}
+
+func (m *MemoryApiTokenRepository) Delete(id uuid.UUID) error {
+	delete(m.tokens, id)
+	return nil
+}
+
+// StubAuthRepository only implements the user lookup.
+type StubAuthRepository struct {
+	repository.IAuthRepository
+	users map[uuid.UUID]entity.User
+}
+
+func (m *StubAuthRepository) FindById(id uuid.UUID) (entity.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return user, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func TestApiTokens(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "ci", Roles: []entity.Role{{
+		Name: "deployer",
+		Permissions: []entity.Permission{
+			{Name: entity.PermissionUsersRead},
+			{Name: entity.PermissionUsersUpdate},
+		},
+	}}}
+	repo := &MemoryApiTokenRepository{tokens: map[uuid.UUID]entity.ApiToken{}}
+	apiTokens := service.NewApiTokenService(repo, &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}, validator.New())
+
+	_, err := apiTokens.Create(request.ApiTokenCreateRequest{
+		UserId: user.Id,
+		Name:   "deploy",
+		Scopes: []string{entity.PermissionUsersDelete},
+	})
+	assert.ErrorIs(t, err, service.ErrApiTokenScope)
+
+	created, err := apiTokens.Create(request.ApiTokenCreateRequest{
+		UserId: user.Id,
+		Name:   "deploy",
+		Scopes: []string{entity.PermissionUsersRead},
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Token, service.ApiTokenPrefix))
+	assert.True(t, strings.HasPrefix(created.Token, created.Prefix))
+	assert.NotEqual(t, created.Token, repo.tokens[created.Id].TokenHash)
+
+	authenticator := middleware.NewAuthenticator(tokenstore.NewMemoryStore(), middleware.FromAuthHeader("Bearer")).
+		WithApiTokens(service.ApiTokenPrefix, handler.ApiTokenVerifier(apiTokens))
+
+	app := fiber.New()
+	app.Get("/users", authenticator.Required(), middleware.RequirePermission(entity.PermissionUsersRead), func(c *fiber.Ctx) error {
+		return c.SendString(middleware.GetPrincipal(c).Username)
+	})
+	app.Put("/users", authenticator.Required(), middleware.RequirePermission(entity.PermissionUsersUpdate), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	call := func(method, token string) int {
+		req := httptest.NewRequest(method, "/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, call("GET", created.Token))
+	assert.NotNil(t, repo.tokens[created.Id].LastUsedAt)
+	// the user holds users.update, but the token was not granted it
+	assert.Equal(t, 403, call("PUT", created.Token))
+	assert.Equal(t, 401, call("GET", service.ApiTokenPrefix+"unknown"))
+
+	expired := repo.tokens[created.Id]
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	repo.tokens[created.Id] = expired
+	assert.Equal(t, 401, call("GET", created.Token))
+}
+
+func TestApiTokenCreateRequiresSession(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "ci", Roles: []entity.Role{{
+		Name:        "deployer",
+		Permissions: []entity.Permission{{Name: entity.PermissionUsersRead}},
+	}}}
+	apiTokens := service.NewApiTokenService(&MemoryApiTokenRepository{tokens: map[uuid.UUID]entity.ApiToken{}},
+		&StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}, validator.New())
+
+	create := func(principal *middleware.Principal) int {
+		app := fiber.New()
+		app.Post("/tokens", func(c *fiber.Ctx) error {
+			middleware.SetPrincipal(c, principal)
+			return c.Next()
+		}, handler.NewApiTokenHandler(apiTokens).Create)
+
+		req := httptest.NewRequest("POST", "/tokens", strings.NewReader(`{"name":"deploy","scopes":["users.read"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 201, create(&middleware.Principal{Id: user.Id.String()}))
+	assert.Equal(t, 403, create(&middleware.Principal{Id: user.Id.String(), ApiTokenId: uuid.NewString()}))
+	// a client signed in by the user cannot mint itself a broader token
+	assert.Equal(t, 403, create(&middleware.Principal{Id: user.Id.String(), ClientId: "third-party", Scopes: []string{"openid"}}))
+}
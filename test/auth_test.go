@@ -0,0 +1,153 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/handler"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthApp(store tokenstore.TokenStore) *fiber.App {
+	authenticator := middleware.NewAuthenticator(store,
+		middleware.FromAuthHeader("Bearer"),
+		middleware.FromCookie("access_token"),
+	)
+
+	app := fiber.New()
+	app.Get("/me", authenticator.Required(), func(c *fiber.Ctx) error {
+		return c.SendString(middleware.GetPrincipal(c).Username)
+	})
+	return app
+}
+
+func TestAuthenticatorSources(t *testing.T) {
+	app := newAuthApp(tokenstore.NewMemoryStore())
+	user := entity.User{Id: uuid.New(), Username: "john"}
+
+	token, err := helper.GenerateAccessToken(user, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	resp, _ := app.Test(req)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ = app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Cookie", "access_token="+token)
+	resp, _ = app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// a Bearer header wins over the cookie, CSRF protection relies on it
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Cookie", "access_token="+token)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	resp, _ = app.Test(req)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	// other schemes are not read and leave the cookie in charge
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Cookie", "access_token="+token)
+	req.Header.Set("Authorization", "Basic x")
+	resp, _ = app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestAuthenticatorRevokedToken(t *testing.T) {
+	store := tokenstore.NewMemoryStore()
+	app := newAuthApp(store)
+	user := entity.User{Id: uuid.New(), Username: "john"}
+
+	token, err := helper.GenerateAccessToken(user, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+
+	claims, err := helper.ParseToken(token, false)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Revoke(claims.ID, time.Now().Add(time.Minute)))
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ := app.Test(req)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestGuest(t *testing.T) {
+	authenticator := middleware.NewAuthenticator(tokenstore.NewMemoryStore())
+	app := fiber.New()
+	app.Post("/login", middleware.Guest(authenticator, middleware.GuestConfig{}), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	req := httptest.NewRequest("POST", "/login", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	token, err := helper.GenerateAccessToken(entity.User{Id: uuid.New(), Username: "john"}, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest("POST", "/login", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ = app.Test(req)
+	assert.Equal(t, 409, resp.StatusCode)
+}
+
+func TestTokenTypes(t *testing.T) {
+	// the same secret signs both kinds of token, only typ tells them apart
+	helper.UseTokenConfig(helper.TokenConfig{AccessSecret: []byte("secret"), RefreshSecret: []byte("secret")})
+	defer helper.UseTokenConfig(helper.TokenConfig{})
+
+	user := entity.User{Id: uuid.New(), Username: "john"}
+	access, err := helper.GenerateAccessToken(user, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+	refresh, err := helper.GenerateRefreshToken(user, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+
+	_, err = helper.ParseToken(access, false)
+	assert.NoError(t, err)
+	_, err = helper.ParseToken(refresh, true)
+	assert.NoError(t, err)
+
+	_, err = helper.ParseToken(access, true)
+	assert.Error(t, err)
+	_, err = helper.ParseToken(refresh, false)
+	assert.Error(t, err)
+}
+
+func TestMe(t *testing.T) {
+	verifiedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := entity.User{Id: uuid.New(), Username: "john", Name: "John", Email: "john@example.com", Status: 1, EmailVerifiedAt: &verifiedAt}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}
+	authHandler := handler.NewAuthHandler(service.NewAuthService(authRepo, nil, nil, nil, nil, nil, nil, nil, service.AuthConfig{}, nil), nil, nil)
+
+	app := fiber.New()
+	app.Get("/me", middleware.NewAuthenticator(tokenstore.NewMemoryStore(), middleware.FromAuthHeader("Bearer")).Required(), authHandler.Me)
+
+	token, err := helper.GenerateAccessToken(user, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body response.AuthJSON
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "john@example.com", body.User.Email)
+	assert.Equal(t, 1, body.User.Status)
+	assert.Equal(t, verifiedAt.Format(time.RFC3339), body.User.EmailVerifiedAt)
+}
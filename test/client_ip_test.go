@@ -0,0 +1,62 @@
+package test
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	defer helper.UseTrustedProxies(nil)
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(helper.ClientIP(c))
+	})
+
+	clientIP := func(header, value string) string {
+		req := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	// app.Test connects from 0.0.0.0, trusted here along with an inner proxy
+	proxies, err := helper.NewTrustedProxies([]string{"0.0.0.0", "10.0.0.0/8"}, "")
+	assert.NoError(t, err)
+	helper.UseTrustedProxies(proxies)
+
+	// the leftmost entry is set by the client and cannot be trusted
+	assert.Equal(t, "203.0.113.7", clientIP(helper.HeaderXForwardedFor, "198.51.100.1, 203.0.113.7, 10.1.2.3"))
+	assert.Equal(t, "0.0.0.0", clientIP("", ""))
+
+	proxies, err = helper.NewTrustedProxies([]string{"0.0.0.0/32"}, helper.HeaderXRealIP)
+	assert.NoError(t, err)
+	helper.UseTrustedProxies(proxies)
+	assert.Equal(t, "203.0.113.7", clientIP(helper.HeaderXRealIP, "203.0.113.7"))
+	assert.Equal(t, "0.0.0.0", clientIP(helper.HeaderXForwardedFor, "203.0.113.7"))
+
+	proxies, err = helper.NewTrustedProxies([]string{"0.0.0.0/32"}, helper.HeaderForwarded)
+	assert.NoError(t, err)
+	helper.UseTrustedProxies(proxies)
+	assert.Equal(t, "2001:db8::1", clientIP(helper.HeaderForwarded, `for="[2001:db8::1]:4711";proto=https`))
+
+	// headers of clients connecting directly are ignored
+	proxies, err = helper.NewTrustedProxies([]string{"10.0.0.0/8"}, "")
+	assert.NoError(t, err)
+	helper.UseTrustedProxies(proxies)
+	assert.Equal(t, "0.0.0.0", clientIP(helper.HeaderXForwardedFor, "203.0.113.7"))
+
+	_, err = helper.NewTrustedProxies([]string{"not-a-cidr"}, "")
+	assert.Error(t, err)
+	_, err = helper.NewTrustedProxies(nil, "X-Client-IP")
+	assert.Error(t, err)
+}
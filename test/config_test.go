@@ -0,0 +1,144 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const configSecrets = `
+database:
+  name: service
+  user: postgres
+  password: database-password
+jwt:
+  secret: access-secret
+  refresh_secret: refresh-secret
+  verification_secret: verification-secret
+  mfa_secret: mfa-secret
+  magic_link_secret: magic-link-secret
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", configSecrets+`
+DATABASE_HOST: db.internal
+database_port: 1111
+log_level: debug
+TRUSTED_PROXIES: [10.0.0.0/8, 192.168.1.1]
+`)
+	dotenv := writeFile(t, ".env", "DATABASE_PORT=2222\nLOG_FILE=dotenv.log\nUNRELATED=1\n")
+	t.Setenv("LOG_FILE", "env.log")
+	t.Setenv("APP_ADDRESS", "0.0.0.0:8080")
+
+	env, err := config.Load([]string{"-config", file, "-env-file", dotenv, "--app-address", "0.0.0.0:9090"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "db.internal", env.DatabaseHost)
+	assert.Equal(t, 2222, env.DatabasePort)
+	assert.Equal(t, "env.log", env.LogFile)
+	assert.Equal(t, "0.0.0.0:9090", env.AppAddress)
+	assert.Equal(t, "debug", env.LogLevel)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, env.TrustedProxies)
+	assert.Equal(t, 15*time.Minute, env.AccessTokenTTL)
+	assert.Equal(t, []string{"http://localhost:5173"}, env.CORSAllowOrigins)
+}
+
+func TestConfigTOML(t *testing.T) {
+	file := writeFile(t, "config.toml", `
+access_token_ttl = "5m"
+cors_allow_origins = ["https://app.example.com", "https://admin.example.com"]
+
+[database]
+name = "service"
+user = "postgres"
+port = 6543
+
+[jwt]
+secret = "access-secret"
+refresh_secret = "refresh-secret"
+verification_secret = "verification-secret"
+mfa_secret = "mfa-secret"
+magic_link_secret = "magic-link-secret"
+`)
+
+	env, err := config.Load([]string{"-config", file, "-env-file", filepath.Join(t.TempDir(), "missing")})
+	assert.NoError(t, err)
+	assert.Equal(t, 6543, env.DatabasePort)
+	assert.Equal(t, 5*time.Minute, env.AccessTokenTTL)
+	assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, env.CORSAllowOrigins)
+}
+
+func TestConfigErrors(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+database:
+  port: abc
+log_level: loud
+access_token_ttl: soon
+token_store: redis
+trusted_proxies: not-an-ip
+unknown_key: 1
+`)
+
+	_, err := config.Load([]string{"-config", file, "-env-file", filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+
+	// every problem is reported at once
+	for _, message := range []string{
+		"unknown keys UNKNOWN_KEY",
+		"DATABASE_PORT: must be an integer",
+		"ACCESS_TOKEN_TTL: must be a duration",
+		"LOG_LEVEL: must be one of trace, debug, info, warn, error, fatal, panic",
+		"DATABASE_NAME: is required",
+		"JWT_SECRET: is required when JWT_KEYS_DIR is not set",
+		"REDIS_URL: is required when TOKEN_STORE is redis",
+		"TRUSTED_PROXIES[0]: must be an IP address or a CIDR",
+	} {
+		assert.Contains(t, err.Error(), message)
+	}
+	assert.NotContains(t, err.Error(), "DATABASE_PORT: must be at least")
+
+	// a refresh token must not verify as an access token
+	file = writeFile(t, "config.yaml", configSecrets)
+	_, err = config.Load([]string{"-config", file, "-env-file", filepath.Join(t.TempDir(), "missing"), "--jwt-refresh-secret", "access-secret"})
+	assert.ErrorContains(t, err, "JWT_REFRESH_SECRET: must differ from JWT_SECRET")
+
+	_, err = config.Load([]string{"-config", writeFile(t, "config.json", "{}")})
+	assert.ErrorContains(t, err, "unsupported configuration file")
+}
+
+func TestConfigRedaction(t *testing.T) {
+	file := writeFile(t, "config.yaml", configSecrets)
+
+	env, err := config.Load([]string{"-config", file, "-env-file", filepath.Join(t.TempDir(), "missing")})
+	assert.NoError(t, err)
+
+	for _, printed := range []string{env.String(), fmt.Sprintf("%v", env), fmt.Sprintf("%+v", env), fmt.Sprintf("%#v", env)} {
+		assert.Contains(t, printed, "DATABASE_USER=postgres\n")
+		assert.Contains(t, printed, "JWT_SECRET=[REDACTED]\n")
+		assert.Contains(t, printed, "DATABASE_PASSWORD=[REDACTED]\n")
+		assert.Contains(t, printed, "CSRF_SECRET=\n")
+		assert.NotContains(t, printed, "access-secret")
+		assert.NotContains(t, printed, "database-password")
+	}
+}
+
+func TestConfigFileFromDotenv(t *testing.T) {
+	file := writeFile(t, "config.yaml", configSecrets+"database_host: db.internal\n")
+	dotenv := writeFile(t, ".env", "CONFIG_FILE="+file+"\n")
+	t.Setenv("CONFIG_FILE", "")
+
+	env, err := config.Load([]string{"-env-file", dotenv})
+	assert.NoError(t, err)
+	assert.Equal(t, "db.internal", env.DatabaseHost)
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFProtect(t *testing.T) {
+	csrf := middleware.NewCSRF(middleware.CSRFConfig{Secret: []byte("secret")})
+
+	app := fiber.New()
+	app.Use(csrf.Protect())
+	app.Get("/csrf", func(c *fiber.Ctx) error {
+		token, err := csrf.Issue(c)
+		if err != nil {
+			return err
+		}
+		return c.SendString(token)
+	})
+	app.All("/mutate", func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/csrf", nil))
+	body, _ := io.ReadAll(resp.Body)
+	token := string(body)
+	assert.Contains(t, resp.Header.Get("Set-Cookie"), "csrf_token="+token)
+
+	send := func(method, cookie, header, authorization string) int {
+		req := httptest.NewRequest(method, "/mutate", nil)
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		if header != "" {
+			req.Header.Set("X-CSRF-Token", header)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	session := "access_token=jwt; csrf_token=" + token
+
+	assert.Equal(t, 403, send("POST", session, "", ""))
+	assert.Equal(t, 200, send("POST", session, token, ""))
+	assert.Equal(t, 200, send("DELETE", "refresh_token=jwt; csrf_token="+token, token, ""))
+	assert.Equal(t, 403, send("POST", "refresh_token=jwt", token, ""))
+
+	// safe methods, requests without credentials and bearer requests
+	assert.Equal(t, 200, send("GET", session, "", ""))
+	assert.Equal(t, 200, send("POST", "", "", ""))
+	assert.Equal(t, 200, send("POST", session, "", "Bearer jwt"))
+
+	// other schemes leave the request to the cookie and are checked
+	assert.Equal(t, 403, send("POST", session, "", "Basic x"))
+	assert.Equal(t, 403, send("POST", session, "", "Bearer"))
+
+	// a token the server did not sign is refused even when both match
+	forged := strings.SplitN(token, ".", 2)[0] + ".forged"
+	assert.Equal(t, 403, send("POST", "access_token=jwt; csrf_token="+forged, forged, ""))
+
+	// and so are tokens signed with another secret
+	other := middleware.NewCSRF(middleware.CSRFConfig{Secret: []byte("other")})
+	app.Get("/other", func(c *fiber.Ctx) error {
+		token, _ := other.Issue(c)
+		return c.SendString(token)
+	})
+	resp, _ = app.Test(httptest.NewRequest("GET", "/other", nil))
+	body, _ = io.ReadAll(resp.Body)
+	foreign := string(body)
+	assert.Equal(t, 403, send("POST", "access_token=jwt; csrf_token="+foreign, foreign, ""))
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/geoip"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeMMDB writes a minimal IPv4 MaxMind database with a single node:
+// 0.0.0.0/1 resolves to low and 128.0.0.0/1 to high.
+func writeMMDB(t *testing.T, path string, low, high geoip.Location) {
+	str := func(s string) []byte { return append([]byte{0x40 | byte(len(s))}, s...) }
+	uint16v := func(v uint16) []byte { return binary.BigEndian.AppendUint16([]byte{0xA2}, v) }
+	uint32v := func(v uint32) []byte { return binary.BigEndian.AppendUint32([]byte{0xC4}, v) }
+	uint64v := func(v uint64) []byte { return binary.BigEndian.AppendUint64([]byte{0x08, 0x02}, v) }
+	mapv := func(pairs ...[]byte) []byte {
+		out := []byte{0xE0 | byte(len(pairs)/2)}
+		for _, pair := range pairs {
+			out = append(out, pair...)
+		}
+		return out
+	}
+	names := func(name string) []byte { return mapv(str("names"), mapv(str("en"), str(name))) }
+	record := func(location geoip.Location) []byte {
+		pairs := [][]byte{str("country"), mapv(str("iso_code"), str(location.Country))}
+		if location.City != "" {
+			pairs = append(pairs, str("city"), names(location.City))
+		}
+		return mapv(pairs...)
+	}
+
+	lowData, highData := record(low), record(high)
+	data := append(append([]byte{}, lowData...), highData...)
+
+	const nodeCount = 1
+	tree := make([]byte, 6)
+	left, right := uint32(nodeCount+16), uint32(nodeCount+16+len(lowData))
+	tree[0], tree[1], tree[2] = byte(left>>16), byte(left>>8), byte(left)
+	tree[3], tree[4], tree[5] = byte(right>>16), byte(right>>8), byte(right)
+
+	metadata := mapv(
+		str("node_count"), uint32v(nodeCount),
+		str("record_size"), uint16v(24),
+		str("ip_version"), uint16v(4),
+		str("database_type"), str("Test-City"),
+		str("languages"), append([]byte{0x01, 0x04}, str("en")...),
+		str("binary_format_major_version"), uint16v(2),
+		str("binary_format_minor_version"), uint16v(0),
+		str("build_epoch"), uint64v(uint64(time.Now().Unix())),
+		str("description"), mapv(str("en"), str("test")),
+	)
+
+	file := append(tree, make([]byte, 16)...)
+	file = append(file, data...)
+	file = append(file, "\xAB\xCD\xEFMaxMind.com"...)
+	file = append(file, metadata...)
+
+	// replace the file atomically, the way database updates should be done
+	tmp := path + ".tmp"
+	assert.NoError(t, os.WriteFile(tmp, file, 0o644))
+	assert.NoError(t, os.Rename(tmp, path))
+}
+
+func TestGeoIPLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	writeMMDB(t, path, geoip.Location{Country: "NL", City: "Amsterdam"}, geoip.Location{Country: "US"})
+
+	db, err := geoip.Open(geoip.Config{Path: path, ReloadInterval: -1})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	location := db.Lookup("10.1.2.3")
+	assert.Equal(t, geoip.Location{Country: "NL", City: "Amsterdam"}, location)
+	assert.Equal(t, "Amsterdam, NL", location.String())
+
+	// served from the cache the second time
+	assert.Equal(t, location, db.Lookup("10.1.2.3"))
+
+	assert.Equal(t, "US", db.Lookup("200.1.1.1").String())
+	assert.Equal(t, geoip.Location{}, db.Lookup("not-an-ip"))
+	assert.Equal(t, "", db.Lookup("not-an-ip").String())
+
+	_, err = geoip.Open(geoip.Config{Path: filepath.Join(t.TempDir(), "missing.mmdb")})
+	assert.Error(t, err)
+}
+
+func TestGeoIPReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	writeMMDB(t, path, geoip.Location{Country: "NL", City: "Amsterdam"}, geoip.Location{Country: "US"})
+
+	db, err := geoip.Open(geoip.Config{Path: path, ReloadInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, "NL", db.Lookup("10.1.2.3").Country)
+
+	writeMMDB(t, path, geoip.Location{Country: "DE", City: "Berlin"}, geoip.Location{Country: "US"})
+	// the replacement has the same size, make sure the time differs too
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, future, future))
+
+	assert.Eventually(t, func() bool {
+		return db.Lookup("10.1.2.3").City == "Berlin"
+	}, time.Second, 10*time.Millisecond)
+
+	reloaded, err := db.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonation(t *testing.T) {
+	admin := entity.User{Id: uuid.New(), Username: "admin", Roles: []entity.Role{{
+		Name:        entity.RoleAdmin,
+		Permissions: []entity.Permission{{Name: entity.PermissionUsersImpersonate}},
+	}}}
+	other := entity.User{Id: uuid.New(), Username: "support", Roles: admin.Roles}
+	user := entity.User{Id: uuid.New(), Username: "john"}
+	manager := entity.User{Id: uuid.New(), Username: "manager", Roles: []entity.Role{{
+		Name:        "manager",
+		Permissions: []entity.Permission{{Name: entity.PermissionRolesManage}},
+	}}}
+
+	store := tokenstore.NewMemoryStore()
+	events := &RecordingSecurityEventService{}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{admin.Id: admin, other.Id: other, user.Id: user, manager.Id: manager}}
+	sessionRepo := &MemorySessionRepository{sessions: map[uuid.UUID]entity.Session{}}
+	serv := service.NewImpersonationService(authRepo, sessionRepo, events, store)
+	client := service.ClientInfo{IP: "10.0.0.1"}
+	session, _ := sessionRepo.Create(entity.Session{UserId: admin.Id, ExpiresAt: time.Now().Add(time.Hour)})
+	sessionId := session.Id
+
+	_, err := serv.Start(admin.Id, sessionId, admin.Id, client)
+	assert.ErrorIs(t, err, service.ErrImpersonateSelf)
+	_, err = serv.Start(admin.Id, sessionId, other.Id, client)
+	assert.ErrorIs(t, err, service.ErrImpersonationForbidden)
+	// the admin lacks roles.manage, so cannot borrow it
+	_, err = serv.Start(admin.Id, sessionId, manager.Id, client)
+	assert.ErrorIs(t, err, service.ErrImpersonationForbidden)
+	_, err = serv.Start(admin.Id, uuid.Nil, user.Id, client)
+	assert.ErrorIs(t, err, service.ErrImpersonationNeedsLogin)
+
+	result, err := serv.Start(admin.Id, sessionId, user.Id, client)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{entity.SecurityEventImpersonationStart}, events.events)
+
+	claims, err := helper.ParseToken(result.AccessToken, false)
+	assert.NoError(t, err)
+	assert.Equal(t, user.Id.String(), claims.Id)
+	assert.Equal(t, sessionId.String(), claims.SessionId)
+	assert.Equal(t, admin.Id.String(), claims.Actor.Subject)
+
+	authenticator := middleware.NewAuthenticator(store)
+	app := fiber.New()
+	app.Get("/me", authenticator.Required(), func(c *fiber.Ctx) error {
+		principal := middleware.GetPrincipal(c)
+		return c.SendString(principal.Username + " as " + principal.ActorUsername)
+	})
+	app.Post("/tokens", authenticator.Required(), middleware.NotImpersonated(), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+result.AccessToken)
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	req = httptest.NewRequest("POST", "/tokens", nil)
+	req.Header.Set("Authorization", "Bearer "+result.AccessToken)
+	resp, _ = app.Test(req)
+	assert.Equal(t, 403, resp.StatusCode)
+
+	// ending hands back a token of the admin and revokes the impersonation
+	token, err := serv.End(result.AccessToken, client)
+	assert.NoError(t, err)
+	claims, err = helper.ParseToken(token, false)
+	assert.NoError(t, err)
+	assert.Equal(t, admin.Id.String(), claims.Id)
+	assert.Nil(t, claims.Actor)
+
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+result.AccessToken)
+	resp, _ = app.Test(req)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	_, err = serv.End(token, client)
+	assert.ErrorIs(t, err, service.ErrNotImpersonating)
+}
+
+func TestImpersonationEndsWithAdminSession(t *testing.T) {
+	admin := entity.User{Id: uuid.New(), Username: "admin", Roles: []entity.Role{{
+		Name:        entity.RoleAdmin,
+		Permissions: []entity.Permission{{Name: entity.PermissionUsersImpersonate}},
+	}}}
+	user := entity.User{Id: uuid.New(), Username: "john"}
+
+	store := tokenstore.NewMemoryStore()
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{admin.Id: admin, user.Id: user}}
+	sessionRepo := &MemorySessionRepository{sessions: map[uuid.UUID]entity.Session{}}
+	serv := service.NewImpersonationService(authRepo, sessionRepo, &RecordingSecurityEventService{}, store)
+	client := service.ClientInfo{IP: "10.0.0.1"}
+	session, _ := sessionRepo.Create(entity.Session{UserId: admin.Id, ExpiresAt: time.Now().Add(time.Hour)})
+
+	result, err := serv.Start(admin.Id, session.Id, user.Id, client)
+	assert.NoError(t, err)
+
+	// the admin signs out everywhere, the impersonation goes with it
+	assert.NoError(t, service.NewSessionService(sessionRepo, store).RevokeAll(admin.Id))
+
+	authenticator := middleware.NewAuthenticator(store)
+	app := fiber.New()
+	app.Get("/me", authenticator.Required(), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+result.AccessToken)
+	resp, _ := app.Test(req)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	_, err = serv.End(result.AccessToken, client)
+	assert.ErrorIs(t, err, service.ErrSessionRevoked)
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePrivateKey(t *testing.T, dir, id string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, id+".pem"), data, 0600))
+}
+
+func TestKeySetRotation(t *testing.T) {
+	previous := helper.AccessKeySet()
+	defer helper.UseAccessKeySet(previous)
+
+	dir := t.TempDir()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	writePrivateKey(t, dir, "2024-01", edKey)
+	writePrivateKey(t, dir, "2024-02", ecKey)
+
+	user := entity.User{Id: uuid.New(), Username: "john"}
+
+	keys, err := helper.LoadKeySet(dir, "2024-01")
+	assert.NoError(t, err)
+	helper.UseAccessKeySet(keys)
+
+	token, err := helper.GenerateAccessToken(user, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+
+	// after rotating, tokens of the previous key still verify
+	keys, err = helper.LoadKeySet(dir, "2024-02")
+	assert.NoError(t, err)
+	helper.UseAccessKeySet(keys)
+
+	claims, err := helper.ParseToken(token, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "john", claims.Username)
+
+	jwks := keys.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+	assert.Equal(t, "ES256", jwks.Keys[1].Alg)
+
+	// once retired, its tokens are rejected
+	assert.NoError(t, os.Remove(filepath.Join(dir, "2024-01.pem")))
+	keys, err = helper.LoadKeySet(dir, "2024-02")
+	assert.NoError(t, err)
+	helper.UseAccessKeySet(keys)
+
+	_, err = helper.ParseToken(token, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/mailer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type MemoryLoginEventRepository struct {
+	events []entity.LoginEvent
+}
+
+func (m *MemoryLoginEventRepository) Create(event entity.LoginEvent) (entity.LoginEvent, error) {
+	event.Id = uuid.New()
+	event.CreatedAt = time.Now()
+	m.events = append(m.events, event)
+	return event, nil
+}
+
+func (m *MemoryLoginEventRepository) FindAllByUserId(userId uuid.UUID, page, pageSize int) ([]entity.LoginEvent, int, error) {
+	var events []entity.LoginEvent
+	for i := len(m.events) - 1; i >= 0; i-- {
+		if m.events[i].UserId == userId {
+			events = append(events, m.events[i])
+		}
+	}
+	return events, len(events), nil
+}
+
+func (m *MemoryLoginEventRepository) CountSuccesses(userId uuid.UUID, device, network string) (int64, error) {
+	var count int64
+	for _, event := range m.events {
+		if event.UserId == userId && event.Success &&
+			(device == "" || event.Device == device) && (network == "" || event.Network == network) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ChannelMailer hands every message to a channel, for mail sent in the
+// background.
+type ChannelMailer struct {
+	messages chan mailer.Message
+}
+
+func (m *ChannelMailer) Send(message mailer.Message) error {
+	m.messages <- message
+	return nil
+}
+
+func TestLoginEventNewSignIn(t *testing.T) {
+	repo := &MemoryLoginEventRepository{}
+	mail := &ChannelMailer{messages: make(chan mailer.Message, 10)}
+	serv := service.NewLoginEventService(repo, service.NewAccountMailer(mail, "http://localhost"))
+	user := entity.User{Id: uuid.New(), Email: "john@example.com"}
+
+	sent := func() bool {
+		select {
+		case <-mail.messages:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}
+
+	laptop := service.ClientInfo{IP: "203.0.113.10", UserAgent: "Firefox", Device: "laptop"}
+
+	// the first sign-in of an account is not news
+	serv.RecordSuccess(user, entity.LoginMethodPassword, laptop)
+	assert.False(t, sent())
+
+	// same device from another address of the same range
+	serv.RecordSuccess(user, entity.LoginMethodPassword, service.ClientInfo{IP: "203.0.113.99", Device: "laptop"})
+	assert.False(t, sent())
+
+	serv.RecordSuccess(user, entity.LoginMethodMagicLink, service.ClientInfo{IP: "203.0.113.10", Device: "phone"})
+	assert.True(t, sent())
+
+	// clients without a device cookie are judged by the range
+	serv.RecordSuccess(user, entity.LoginMethodPassword, service.ClientInfo{IP: "203.0.113.20"})
+	assert.False(t, sent())
+	serv.RecordSuccess(user, entity.LoginMethodPassword, service.ClientInfo{IP: "198.51.100.7"})
+	assert.True(t, sent())
+
+	serv.RecordFailure(user, entity.LoginMethodPassword, "invalid_password", service.ClientInfo{IP: "192.0.2.1"})
+	assert.False(t, sent())
+
+	events, total, err := serv.FindAllByUserId(user.Id, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, total)
+	assert.False(t, events[0].Success)
+	assert.Equal(t, "invalid_password", events[0].FailureReason)
+	assert.Equal(t, "192.0.2.0/24", events[0].Network)
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type MemoryLoginThrottleRepository struct {
+	throttles map[string]entity.LoginThrottle
+}
+
+func (m *MemoryLoginThrottleRepository) Find(key string) (entity.LoginThrottle, error) {
+	throttle, ok := m.throttles[key]
+	if !ok {
+		throttle.Key = key
+	}
+	return throttle, nil
+}
+
+func (m *MemoryLoginThrottleRepository) RecordFailure(key string, failedAt, since time.Time) (entity.LoginThrottle, error) {
+	throttle, _ := m.Find(key)
+	if throttle.LastFailureAt.Before(since) {
+		throttle.Failures = 0
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = failedAt
+	m.throttles[key] = throttle
+	return throttle, nil
+}
+
+func (m *MemoryLoginThrottleRepository) Lock(key string, until time.Time) error {
+	throttle := m.throttles[key]
+	throttle.LockedUntil = &until
+	throttle.Failures = 0
+	m.throttles[key] = throttle
+	return nil
+}
+
+func (m *MemoryLoginThrottleRepository) Reset(key string) error {
+	delete(m.throttles, key)
+	return nil
+}
+
+func (m *MemoryLoginThrottleRepository) DeleteStale(before, now time.Time) (int64, error) {
+	var count int64
+	for key, throttle := range m.throttles {
+		if throttle.LastFailureAt.Before(before) && (throttle.LockedUntil == nil || throttle.LockedUntil.Before(now)) {
+			delete(m.throttles, key)
+			count++
+		}
+	}
+	return count, nil
+}
+
+type RecordingSecurityEventService struct {
+	events []string
+}
+
+func (m *RecordingSecurityEventService) Record(event string, userId *uuid.UUID, client service.ClientInfo, details string) {
+	m.events = append(m.events, event)
+}
+
+func TestLoginThrottleBackoff(t *testing.T) {
+	repo := &MemoryLoginThrottleRepository{throttles: map[string]entity.LoginThrottle{}}
+	throttle := service.NewLoginThrottleService(repo, nil, &RecordingSecurityEventService{}, service.ThrottleConfig{
+		FreeAttempts: 2,
+		BaseDelay:    time.Minute,
+	})
+	client := service.ClientInfo{IP: "10.0.0.1"}
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, throttle.Check("john@example.com", client.IP))
+		throttle.RecordFailure("john@example.com", nil, client)
+	}
+
+	var throttleErr *service.ThrottleError
+	err := throttle.Check("john@example.com", client.IP)
+	assert.True(t, errors.As(err, &throttleErr))
+	assert.ErrorIs(t, err, service.ErrTooManyAttempts)
+	assert.InDelta(t, time.Minute.Seconds(), throttleErr.RetryAfter.Seconds(), 1)
+
+	// the address stays throttled for other accounts
+	assert.ErrorIs(t, throttle.Check("jane@example.com", client.IP), service.ErrTooManyAttempts)
+	assert.NoError(t, throttle.Check("jane@example.com", "10.0.0.2"))
+}
+
+func TestLoginThrottleLockout(t *testing.T) {
+	repo := &MemoryLoginThrottleRepository{throttles: map[string]entity.LoginThrottle{}}
+	events := &RecordingSecurityEventService{}
+	throttle := service.NewLoginThrottleService(repo, nil, events, service.ThrottleConfig{
+		MaxFailures:     3,
+		LockoutDuration: time.Hour,
+	})
+
+	for i := 0; i < 3; i++ {
+		throttle.RecordFailure("john@example.com", nil, service.ClientInfo{IP: uuid.NewString()})
+	}
+
+	assert.ErrorIs(t, throttle.Check("john@example.com", "10.0.0.9"), service.ErrAccountLocked)
+	assert.Equal(t, []string{entity.SecurityEventAccountLocked}, events.events)
+
+	throttle.RecordSuccess("john@example.com")
+	assert.NoError(t, throttle.Check("john@example.com", "10.0.0.9"))
+}
+
+func TestLoginThrottleDecay(t *testing.T) {
+	repo := &MemoryLoginThrottleRepository{throttles: map[string]entity.LoginThrottle{}}
+	throttle := service.NewLoginThrottleService(repo, nil, &RecordingSecurityEventService{}, service.ThrottleConfig{
+		FreeAttempts:  2,
+		BaseDelay:     time.Minute,
+		MaxFailures:   3,
+		Window:        time.Hour,
+		PruneInterval: -1,
+	})
+	client := service.ClientInfo{IP: "10.0.0.1"}
+
+	// two failures, both counters near their limits, last seen two hours ago
+	stale := time.Now().Add(-2 * time.Hour)
+	repo.throttles["account:john@example.com"] = entity.LoginThrottle{Key: "account:john@example.com", Failures: 2, LastFailureAt: stale}
+	repo.throttles["ip:10.0.0.1"] = entity.LoginThrottle{Key: "ip:10.0.0.1", Failures: 20, LastFailureAt: stale}
+	assert.NoError(t, throttle.Check("john@example.com", client.IP))
+
+	// the next typo starts over instead of locking the account
+	throttle.RecordFailure("john@example.com", nil, client)
+	assert.NoError(t, throttle.Check("john@example.com", client.IP))
+	assert.Equal(t, 1, repo.throttles["account:john@example.com"].Failures)
+	assert.Equal(t, 1, repo.throttles["ip:10.0.0.1"].Failures)
+}
+
+func TestLoginThrottleLockoutExpires(t *testing.T) {
+	repo := &MemoryLoginThrottleRepository{throttles: map[string]entity.LoginThrottle{}}
+	throttle := service.NewLoginThrottleService(repo, nil, &RecordingSecurityEventService{}, service.ThrottleConfig{
+		MaxFailures:     3,
+		LockoutDuration: time.Hour,
+		PruneInterval:   -1,
+	})
+
+	for i := 0; i < 3; i++ {
+		throttle.RecordFailure("john@example.com", nil, service.ClientInfo{IP: uuid.NewString()})
+	}
+	assert.ErrorIs(t, throttle.Check("john@example.com", "10.0.0.9"), service.ErrAccountLocked)
+
+	// the lockout runs out, one more typo must not lock the account again
+	account := repo.throttles["account:john@example.com"]
+	expired := time.Now().Add(-time.Minute)
+	account.LockedUntil = &expired
+	repo.throttles[account.Key] = account
+
+	throttle.RecordFailure("john@example.com", nil, service.ClientInfo{IP: uuid.NewString()})
+	assert.NoError(t, throttle.Check("john@example.com", "10.0.0.9"))
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMagicLinkConsume(t *testing.T) {
+	verifiedAt := time.Now()
+	user := entity.User{Id: uuid.New(), Email: "john@example.com", EmailVerifiedAt: &verifiedAt}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}
+	throttle := service.NewLoginThrottleService(&MemoryLoginThrottleRepository{throttles: map[string]entity.LoginThrottle{}}, nil, &RecordingSecurityEventService{}, service.ThrottleConfig{})
+	serv := service.NewMagicLinkService(authRepo, throttle, service.NewLoginEventService(&MemoryLoginEventRepository{}, nil), tokenstore.NewMemoryStore(), nil, validator.New())
+	client := service.ClientInfo{IP: "10.0.0.1"}
+
+	device := helper.HashToken("device-a")
+	token, err := helper.GenerateMagicLinkToken(user, device)
+	assert.NoError(t, err)
+
+	// a link only works in the browser it was requested from
+	_, err = serv.Consume(request.MagicLinkConsumeRequest{Token: token}, helper.HashToken("device-b"), client)
+	assert.ErrorIs(t, err, service.ErrInvalidMagicLink)
+	_, err = serv.Consume(request.MagicLinkConsumeRequest{Token: token}, "", client)
+	assert.ErrorIs(t, err, service.ErrInvalidMagicLink)
+
+	result, err := serv.Consume(request.MagicLinkConsumeRequest{Token: token}, device, client)
+	assert.NoError(t, err)
+	assert.Equal(t, user.Id, result.Id)
+
+	// and only once
+	_, err = serv.Consume(request.MagicLinkConsumeRequest{Token: token}, device, client)
+	assert.ErrorIs(t, err, service.ErrInvalidMagicLink)
+
+	_, err = serv.Consume(request.MagicLinkConsumeRequest{Token: "not-a-token"}, device, client)
+	assert.ErrorIs(t, err, service.ErrInvalidMagicLink)
+}
+
+func TestMagicLinkEmailChanged(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Email: "john@example.com"}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}
+	throttle := service.NewLoginThrottleService(&MemoryLoginThrottleRepository{throttles: map[string]entity.LoginThrottle{}}, nil, &RecordingSecurityEventService{}, service.ThrottleConfig{})
+	serv := service.NewMagicLinkService(authRepo, throttle, service.NewLoginEventService(&MemoryLoginEventRepository{}, nil), tokenstore.NewMemoryStore(), nil, validator.New())
+
+	device := helper.HashToken("device-a")
+	token, err := helper.GenerateMagicLinkToken(user, device)
+	assert.NoError(t, err)
+
+	// the address changes before the link is opened
+	user.Email = "someone-else@example.com"
+	authRepo.users[user.Id] = user
+
+	_, err = serv.Consume(request.MagicLinkConsumeRequest{Token: token}, device, service.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, service.ErrInvalidMagicLink)
+	assert.Nil(t, authRepo.users[user.Id].EmailVerifiedAt)
+}
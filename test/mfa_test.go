@@ -0,0 +1,130 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type MemoryMfaRepository struct {
+	steps         map[int64]bool
+	recoveryCodes map[string]bool
+}
+
+func (m *MemoryMfaRepository) SaveSecret(userId uuid.UUID, secret string) error {
+	return nil
+}
+
+func (m *MemoryMfaRepository) Enable(userId uuid.UUID, codes []entity.MfaRecoveryCode) error {
+	return nil
+}
+
+func (m *MemoryMfaRepository) Disable(userId uuid.UUID) error {
+	return nil
+}
+
+func (m *MemoryMfaRepository) UseStep(userId uuid.UUID, step int64) (bool, error) {
+	if m.steps[step] {
+		return false, nil
+	}
+	m.steps[step] = true
+	return true, nil
+}
+
+func (m *MemoryMfaRepository) ConsumeRecoveryCode(userId uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	unused, ok := m.recoveryCodes[codeHash]
+	if !ok || !unused {
+		return false, nil
+	}
+	m.recoveryCodes[codeHash] = false
+	return true, nil
+}
+
+func (m *MemoryMfaRepository) CountRecoveryCodes(userId uuid.UUID) (int64, error) {
+	var count int64
+	for _, unused := range m.recoveryCodes {
+		if unused {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func newMfaService(t *testing.T, config service.ThrottleConfig) (service.IMfaService, entity.User, *MemoryMfaRepository, *MemoryLoginEventRepository) {
+	secret, err := helper.GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	user := entity.User{Id: uuid.New(), Email: "john@example.com", MfaEnabled: true, MfaSecret: secret}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}
+	mfaRepo := &MemoryMfaRepository{
+		steps:         map[int64]bool{},
+		recoveryCodes: map[string]bool{helper.HashToken("abcde12345"): true},
+	}
+	throttle := service.NewLoginThrottleService(&MemoryLoginThrottleRepository{throttles: map[string]entity.LoginThrottle{}}, nil, &RecordingSecurityEventService{}, config)
+	loginEvents := &MemoryLoginEventRepository{}
+
+	serv := service.NewMfaService(authRepo, mfaRepo, throttle, service.NewLoginEventService(loginEvents, nil), tokenstore.NewMemoryStore(), nil, "", validator.New())
+	return serv, user, mfaRepo, loginEvents
+}
+
+func TestMfaVerifyChallengeAttempts(t *testing.T) {
+	serv, user, mfaRepo, loginEvents := newMfaService(t, service.ThrottleConfig{FreeAttempts: 100, MaxFailures: 100, MaxChallengeFailures: 3})
+	client := service.ClientInfo{IP: "10.0.0.1"}
+
+	challenge, err := serv.Challenge(user)
+	assert.NoError(t, err)
+	verify := func(code string) error {
+		_, err := serv.Verify(request.MfaVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: code}, client)
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, verify("wrong-code"), service.ErrInvalidMfaCode)
+	}
+
+	// the challenge is gone after three wrong codes, even for the right one
+	code, err := helper.TOTPCode(user.MfaSecret, time.Now())
+	assert.NoError(t, err)
+	assert.ErrorIs(t, verify(code), service.ErrInvalidMfaChallenge)
+
+	challenge, err = serv.Challenge(user)
+	assert.NoError(t, err)
+	assert.NoError(t, verify(code))
+
+	// the history has the wrong codes and the completed sign-in
+	assert.Len(t, loginEvents.events, 4)
+	last := loginEvents.events[3]
+	assert.True(t, last.Success)
+	assert.Equal(t, entity.LoginMethodMfa, last.Method)
+
+	// a completed challenge does not burn a recovery code
+	assert.ErrorIs(t, verify("abcde-12345"), service.ErrInvalidMfaChallenge)
+	remaining, _ := mfaRepo.CountRecoveryCodes(user.Id)
+	assert.Equal(t, int64(1), remaining)
+}
+
+func TestMfaVerifyThrottle(t *testing.T) {
+	serv, user, _, _ := newMfaService(t, service.ThrottleConfig{FreeAttempts: 2, BaseDelay: time.Minute})
+	client := service.ClientInfo{IP: "10.0.0.1"}
+
+	for i := 0; i < 2; i++ {
+		challenge, err := serv.Challenge(user)
+		assert.NoError(t, err)
+		_, err = serv.Verify(request.MfaVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: "wrong-code"}, client)
+		assert.ErrorIs(t, err, service.ErrInvalidMfaCode)
+	}
+
+	// fresh challenges do not reset the account throttle
+	challenge, err := serv.Challenge(user)
+	assert.NoError(t, err)
+	_, err = serv.Verify(request.MfaVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: "wrong-code"}, client)
+	assert.ErrorIs(t, err, service.ErrTooManyAttempts)
+}
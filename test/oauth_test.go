@@ -0,0 +1,324 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/handler"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/response"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type MemoryOAuthRepository struct {
+	clients map[string]entity.OAuthClient
+	codes   map[string]entity.OAuthAuthorizationCode
+}
+
+func (m *MemoryOAuthRepository) CreateClient(client entity.OAuthClient) (entity.OAuthClient, error) {
+	client.Id = uuid.New()
+	m.clients[client.ClientId] = client
+	return client, nil
+}
+
+func (m *MemoryOAuthRepository) FindAllClients() ([]entity.OAuthClient, error) {
+	clients := []entity.OAuthClient{}
+	for _, client := range m.clients {
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+func (m *MemoryOAuthRepository) FindClientByClientId(clientId string) (entity.OAuthClient, error) {
+	client, ok := m.clients[clientId]
+	if !ok {
+		return client, gorm.ErrRecordNotFound
+	}
+	return client, nil
+}
+
+func (m *MemoryOAuthRepository) DeleteClient(id uuid.UUID) error {
+	return nil
+}
+
+func (m *MemoryOAuthRepository) CreateCode(code entity.OAuthAuthorizationCode) (entity.OAuthAuthorizationCode, error) {
+	code.Id = uuid.New()
+	m.codes[code.CodeHash] = code
+	return code, nil
+}
+
+func (m *MemoryOAuthRepository) FindCodeByHash(codeHash string) (entity.OAuthAuthorizationCode, error) {
+	code, ok := m.codes[codeHash]
+	if !ok {
+		return code, gorm.ErrRecordNotFound
+	}
+	return code, nil
+}
+
+func (m *MemoryOAuthRepository) ConsumeCode(id uuid.UUID, usedAt time.Time) (bool, error) {
+	for hash, code := range m.codes {
+		if code.Id == id && code.UsedAt == nil {
+			code.UsedAt = &usedAt
+			m.codes[hash] = code
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newOAuthService() (service.IOAuthService, *MemoryOAuthRepository) {
+	repo := &MemoryOAuthRepository{
+		clients: map[string]entity.OAuthClient{},
+		codes:   map[string]entity.OAuthAuthorizationCode{},
+	}
+	tokens := service.NewTokenService(nil, nil, nil, tokenstore.NewMemoryStore())
+	return service.NewOAuthService(repo, nil, nil, tokens, service.OAuthConfig{}, validator.New()), repo
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	oauth, _ := newOAuthService()
+
+	client, err := oauth.CreateClient(request.OAuthClientCreateRequest{
+		Name:         "Billing",
+		Scopes:       []string{"invoices.read", "invoices.write"},
+		GrantTypes:   []string{entity.GrantClientCredentials},
+		Confidential: true,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, client.ClientSecret)
+
+	_, err = oauth.Token(request.TokenRequest{
+		GrantType:    entity.GrantClientCredentials,
+		ClientId:     client.ClientId,
+		ClientSecret: "wrong",
+	}, service.ClientInfo{})
+	assert.ErrorIs(t, err, service.ErrInvalidOAuthClient)
+
+	result, err := oauth.Token(request.TokenRequest{
+		GrantType:    entity.GrantClientCredentials,
+		ClientId:     client.ClientId,
+		ClientSecret: client.ClientSecret,
+		Scope:        "invoices.read",
+	}, service.ClientInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, "invoices.read", result.Scope)
+
+	claims, err := helper.ParseToken(result.AccessToken, false)
+	assert.NoError(t, err)
+	assert.Equal(t, client.ClientId, claims.ClientId)
+	assert.Empty(t, claims.Id)
+}
+
+func TestOAuthAuthorizationCodeRequiresVerifier(t *testing.T) {
+	oauth, repo := newOAuthService()
+
+	client, err := oauth.CreateClient(request.OAuthClientCreateRequest{
+		Name:         "SPA",
+		RedirectURIs: []string{"http://localhost:5173/callback"},
+		GrantTypes:   []string{entity.GrantAuthorizationCode},
+	})
+	assert.NoError(t, err)
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	authorize := request.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientId:            client.ClientId,
+		RedirectURI:         "http://localhost:5173/callback",
+		State:               "xyz",
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+		CodeChallengeMethod: "S256",
+	}
+
+	oauthClient, err := oauth.ValidateAuthorization(authorize)
+	assert.NoError(t, err)
+
+	// PKCE is mandatory
+	withoutPKCE := authorize
+	withoutPKCE.CodeChallenge = ""
+	_, err = oauth.Authorize(withoutPKCE, oauthClient, uuid.New(), uuid.Nil)
+	var oauthErr *service.OAuthError
+	assert.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_request", oauthErr.Code)
+
+	redirect, err := oauth.Authorize(authorize, oauthClient, uuid.New(), uuid.Nil)
+	assert.NoError(t, err)
+	assert.Contains(t, redirect, "state=xyz")
+	assert.Len(t, repo.codes, 1)
+
+	parsed, err := url.Parse(redirect)
+	assert.NoError(t, err)
+	code := parsed.Query().Get("code")
+
+	_, err = oauth.Token(request.TokenRequest{
+		GrantType:    entity.GrantAuthorizationCode,
+		ClientId:     client.ClientId,
+		Code:         code,
+		RedirectURI:  "http://localhost:5173/callback",
+		CodeVerifier: "another-verifier",
+	}, service.ClientInfo{})
+	assert.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_grant", oauthErr.Code)
+}
+
+func TestOAuthIntrospectAndRevoke(t *testing.T) {
+	oauth, _ := newOAuthService()
+
+	newClient := func(name string) response.OAuthClientResponse {
+		client, err := oauth.CreateClient(request.OAuthClientCreateRequest{
+			Name:         name,
+			GrantTypes:   []string{entity.GrantClientCredentials},
+			Confidential: true,
+		})
+		assert.NoError(t, err)
+		return client
+	}
+	billing, reports := newClient("Billing"), newClient("Reports")
+
+	token, err := oauth.Token(request.TokenRequest{
+		GrantType:    entity.GrantClientCredentials,
+		ClientId:     billing.ClientId,
+		ClientSecret: billing.ClientSecret,
+	}, service.ClientInfo{})
+	assert.NoError(t, err)
+
+	introspect := func() response.TokenIntrospectionResponse {
+		result, err := oauth.Introspect(request.TokenIntrospectionRequest{
+			Token:        token.AccessToken,
+			ClientId:     reports.ClientId,
+			ClientSecret: reports.ClientSecret,
+		})
+		assert.NoError(t, err)
+		return result
+	}
+
+	_, err = oauth.Introspect(request.TokenIntrospectionRequest{Token: token.AccessToken, ClientId: reports.ClientId})
+	assert.ErrorIs(t, err, service.ErrInvalidOAuthClient)
+
+	result := introspect()
+	assert.True(t, result.Active)
+	assert.Equal(t, billing.ClientId, result.ClientId)
+	assert.Equal(t, "Bearer", result.TokenType)
+
+	// another client may inspect the token but not revoke it
+	err = oauth.Revoke(request.TokenRevocationRequest{
+		Token:        token.AccessToken,
+		ClientId:     reports.ClientId,
+		ClientSecret: reports.ClientSecret,
+	})
+	var oauthErr *service.OAuthError
+	assert.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "unauthorized_client", oauthErr.Code)
+	assert.True(t, introspect().Active)
+
+	err = oauth.Revoke(request.TokenRevocationRequest{
+		Token:        token.AccessToken,
+		ClientId:     billing.ClientId,
+		ClientSecret: billing.ClientSecret,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, response.TokenIntrospectionResponse{Active: false}, introspect())
+
+	// invalid tokens are not an error
+	assert.NoError(t, oauth.Revoke(request.TokenRevocationRequest{
+		Token:        "not-a-token",
+		ClientId:     billing.ClientId,
+		ClientSecret: billing.ClientSecret,
+	}))
+}
+
+func TestOAuthClientTokenPermissions(t *testing.T) {
+	admin := entity.User{Id: uuid.New(), Username: "admin", Roles: []entity.Role{{
+		Name:        entity.RoleAdmin,
+		Permissions: []entity.Permission{{Name: entity.PermissionUsersDelete}},
+	}}}
+
+	authenticator := middleware.NewAuthenticator(tokenstore.NewMemoryStore())
+	app := fiber.New()
+	app.Delete("/users/:id", authenticator.Required(), middleware.RequirePermission(entity.PermissionUsersDelete), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	status := func(clientId, scope string) int {
+		token, err := helper.GenerateAccessToken(admin, uuid.NewString(), clientId, scope)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest("DELETE", "/users/"+uuid.NewString(), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, status("", ""))
+	// a client signed in by the admin does not get the admin's rights
+	assert.Equal(t, 403, status("third-party", "openid"))
+	assert.Equal(t, 200, status("third-party", "openid "+entity.PermissionUsersDelete))
+
+	token, err := helper.GenerateAccessToken(admin, uuid.NewString(), "third-party", "openid")
+	assert.NoError(t, err)
+	claims, err := helper.ParseToken(token, false)
+	assert.NoError(t, err)
+	assert.Empty(t, claims.Permissions)
+	assert.Empty(t, claims.Roles)
+
+	// a scope is required even when the token lists the permission
+	principal := &middleware.Principal{ClientId: "third-party", Permissions: []string{entity.PermissionUsersDelete}}
+	assert.False(t, principal.HasPermission(entity.PermissionUsersDelete))
+}
+
+// authorize calls the authorize endpoint of a new client as the principal
+// and returns where it redirects to, or the status code of an error.
+func authorize(t *testing.T, principal *middleware.Principal) string {
+	oauth, _ := newOAuthService()
+	client, err := oauth.CreateClient(request.OAuthClientCreateRequest{
+		Name:         "SPA",
+		RedirectURIs: []string{"http://localhost:5173/callback"},
+		GrantTypes:   []string{entity.GrantAuthorizationCode},
+	})
+	assert.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/oauth/authorize", func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, principal)
+		return c.Next()
+	}, handler.NewOAuthHandler(oauth).Authorize)
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {client.ClientId},
+		"redirect_uri":          {"http://localhost:5173/callback"},
+		"code_challenge":        {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+		"code_challenge_method": {"S256"},
+	}
+	resp, err := app.Test(httptest.NewRequest("GET", "/oauth/authorize?"+query.Encode(), nil))
+	assert.NoError(t, err)
+	if resp.StatusCode != fiber.StatusFound {
+		return strconv.Itoa(resp.StatusCode)
+	}
+	return resp.Header.Get(fiber.HeaderLocation)
+}
+
+func TestOAuthAuthorizePrincipals(t *testing.T) {
+	userId := uuid.NewString()
+
+	assert.Contains(t, authorize(t, &middleware.Principal{Id: userId}), "http://localhost:5173/callback?code=")
+	// an impersonation must not be turned into a long-lived token pair
+	assert.Equal(t, "403", authorize(t, &middleware.Principal{Id: userId, ActorId: uuid.NewString()}))
+	// a personal access token is sent to sign in, whatever its scopes
+	assert.Contains(t, authorize(t, &middleware.Principal{Id: userId, ApiTokenId: uuid.NewString()}), "?redirect=")
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/fatihrizqon/go-fiber-service/password"
+	"github.com/stretchr/testify/assert"
+)
+
+func sha1Hex(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func violatedRules(t *testing.T, err error) []string {
+	var policyErr *password.PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("expected a policy error, got %v", err)
+	}
+	rules := []string{}
+	for _, violation := range policyErr.Violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestPasswordPolicyRules(t *testing.T) {
+	policy := password.NewPolicy(password.PolicyConfig{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		DenyContext:   true,
+	}, nil)
+	ctx := password.PolicyContext{Username: "johndoe", Email: "jd.work@example.com", Name: "John Doe"}
+
+	assert.Equal(t, []string{password.RuleMinLength, password.RuleUpper, password.RuleDigit, password.RuleSymbol},
+		violatedRules(t, policy.Check("short", ctx)))
+	assert.Equal(t, []string{password.RuleContext}, violatedRules(t, policy.Check("Johndoe-2024!", ctx)))
+	assert.Equal(t, []string{password.RuleContext}, violatedRules(t, policy.Check("JD.WORK-2024!", ctx)))
+	assert.NoError(t, policy.Check("Tr0ub4dor&3x", ctx))
+}
+
+func TestPasswordPolicyMaxBytes(t *testing.T) {
+	policy := password.NewPolicy(password.PolicyConfig{MaxBytes: password.BcryptMaxBytes}, nil)
+
+	assert.NoError(t, policy.Check(strings.Repeat("a", password.BcryptMaxBytes), password.PolicyContext{}))
+	assert.Equal(t, []string{password.RuleMaxLength},
+		violatedRules(t, policy.Check(strings.Repeat("a", password.BcryptMaxBytes+1), password.PolicyContext{})))
+	// 40 characters but 80 bytes, within MaxLength yet too long for bcrypt
+	assert.Equal(t, []string{password.RuleMaxLength},
+		violatedRules(t, policy.Check(strings.Repeat("é", 40), password.PolicyContext{})))
+	// over both limits, reported once
+	assert.Equal(t, []string{password.RuleMaxLength},
+		violatedRules(t, policy.Check(strings.Repeat("a", 200), password.PolicyContext{})))
+}
+
+func TestPasswordPolicyHistory(t *testing.T) {
+	hasher, err := password.New(password.Config{Argon2id: testArgon2id})
+	assert.NoError(t, err)
+
+	previous, err := hasher.Hash("old-password-1")
+	assert.NoError(t, err)
+
+	policy := password.NewPolicy(password.PolicyConfig{HistorySize: 3}, hasher)
+	ctx := password.PolicyContext{History: []string{previous}}
+
+	assert.Equal(t, []string{password.RuleHistory}, violatedRules(t, policy.Check("old-password-1", ctx)))
+	assert.NoError(t, policy.Check("new-password-2", ctx))
+}
+
+func TestBreachCorpus(t *testing.T) {
+	breached := []string{"password123", "letmein", "qwerty"}
+
+	// a directory of range files, as served by the k-anonymity API
+	dir := t.TempDir()
+	for _, value := range breached {
+		hash := sha1Hex(value)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(hash[5:]+":42\r\n"), 0o644))
+	}
+
+	// one file of full hashes ordered by hash, with some filler
+	lines := []string{}
+	for _, value := range append(breached, "a", "b", "c", "d", "e", "f") {
+		lines = append(lines, sha1Hex(value)+":7")
+	}
+	sort.Strings(lines)
+	file := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	assert.NoError(t, os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+
+	for _, path := range []string{dir, file} {
+		corpus, err := password.NewBreachCorpus(path, 1)
+		assert.NoError(t, err)
+
+		for _, value := range breached {
+			count, err := corpus.Count(value)
+			assert.NoError(t, err)
+			assert.Positive(t, count, value)
+		}
+
+		count, err := corpus.Count("correct horse battery staple")
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+
+		policy := password.NewPolicy(password.PolicyConfig{Breaches: corpus}, nil)
+		assert.Equal(t, []string{password.RuleBreached}, violatedRules(t, policy.Check("password123", password.PolicyContext{})))
+	}
+
+	corpus, err := password.NewBreachCorpus(dir, 100)
+	assert.NoError(t, err)
+	count, err := corpus.Count("letmein")
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
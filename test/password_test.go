@@ -0,0 +1,137 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/password"
+	"github.com/stretchr/testify/assert"
+)
+
+// cheap parameters, the tests are about the encoding and not the cost
+var testArgon2id = password.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher, err := password.New(password.Config{Algorithm: "argon2id", Argon2id: testArgon2id})
+	assert.NoError(t, err)
+
+	hashed, err := hasher.Hash("correct horse battery staple")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	assert.NoError(t, hasher.Verify("correct horse battery staple", hashed))
+	assert.ErrorIs(t, hasher.Verify("wrong", hashed), password.ErrMismatch)
+	assert.False(t, hasher.NeedsRehash(hashed))
+
+	stronger, err := password.New(password.Config{Argon2id: password.Argon2idParams{Memory: 2048, Iterations: 1, Parallelism: 1}})
+	assert.NoError(t, err)
+	assert.NoError(t, stronger.Verify("correct horse battery staple", hashed))
+	assert.True(t, stronger.NeedsRehash(hashed))
+}
+
+func TestHasherUpgradesBcrypt(t *testing.T) {
+	legacy, err := password.New(password.Config{Algorithm: "bcrypt", Bcrypt: password.BcryptParams{Cost: 4}})
+	assert.NoError(t, err)
+
+	hashed, err := legacy.Hash("secret-password")
+	assert.NoError(t, err)
+	assert.False(t, legacy.NeedsRehash(hashed))
+
+	hasher, err := password.New(password.Config{Argon2id: testArgon2id})
+	assert.NoError(t, err)
+
+	assert.NoError(t, hasher.Verify("secret-password", hashed))
+	assert.ErrorIs(t, hasher.Verify("wrong", hashed), password.ErrMismatch)
+	assert.True(t, hasher.NeedsRehash(hashed))
+
+	assert.ErrorIs(t, hasher.Verify("secret-password", "plain"), password.ErrUnknownHash)
+
+	_, err = password.New(password.Config{Algorithm: "md5"})
+	assert.Error(t, err)
+}
+
+// BlockingHasher holds every call until release is closed.
+type BlockingHasher struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *BlockingHasher) Hash(password string) (string, error) {
+	h.started <- struct{}{}
+	<-h.release
+	return password, nil
+}
+
+func (h *BlockingHasher) Verify(password, encoded string) error {
+	return nil
+}
+
+func (h *BlockingHasher) NeedsRehash(encoded string) bool {
+	return false
+}
+
+func TestPasswordPoolSaturation(t *testing.T) {
+	hasher := &BlockingHasher{started: make(chan struct{}, 2), release: make(chan struct{})}
+	pool := password.NewPool(hasher, password.PoolConfig{
+		Workers:      1,
+		QueueSize:    1,
+		QueueTimeout: time.Minute,
+		RetryAfter:   3 * time.Second,
+	})
+
+	done := make(chan error, 2)
+	go func() {
+		_, err := pool.Hash("first")
+		done <- err
+	}()
+	<-hasher.started
+
+	go func() {
+		_, err := pool.Hash("queued")
+		done <- err
+	}()
+	assert.Eventually(t, func() bool { return pool.Stats().Queued == 1 }, time.Second, time.Millisecond)
+
+	// the only worker is busy and the queue is full
+	_, err := pool.Hash("rejected")
+	var saturated *password.SaturatedError
+	assert.True(t, errors.As(err, &saturated))
+	assert.ErrorIs(t, err, password.ErrPoolSaturated)
+	assert.Equal(t, 3*time.Second, saturated.RetryAfter)
+
+	stats := pool.Stats()
+	assert.Equal(t, int64(1), stats.Active)
+	assert.Equal(t, uint64(1), stats.Rejected)
+
+	close(hasher.release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-done)
+	assert.Equal(t, uint64(2), pool.Stats().Completed)
+}
+
+func TestPasswordPoolContext(t *testing.T) {
+	hasher := &BlockingHasher{started: make(chan struct{}, 1), release: make(chan struct{})}
+	pool := password.NewPool(hasher, password.PoolConfig{Workers: 1, QueueTimeout: 10 * time.Millisecond})
+
+	go pool.Hash("busy")
+	<-hasher.started
+	defer close(hasher.release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := pool.HashContext(ctx, "cancelled")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// services hold a plain Hasher, the helpers still reach the pool
+	var plain password.Hasher = pool
+	_, err = password.HashContext(ctx, plain, "cancelled")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, password.VerifyContext(ctx, plain, "cancelled", "cancelled"), context.Canceled)
+
+	_, err = pool.Hash("timed out")
+	assert.ErrorIs(t, err, password.ErrPoolSaturated)
+	assert.Equal(t, int64(0), pool.Stats().Queued)
+}
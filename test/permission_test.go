@@ -0,0 +1,93 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/middleware"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPermissionApp mirrors the guarded /api/v1 group of the router with
+// handlers that always succeed.
+func newPermissionApp(store tokenstore.TokenStore) *fiber.App {
+	authenticator := middleware.NewAuthenticator(store, middleware.FromAuthHeader("Bearer"))
+	ok := func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	}
+
+	app := fiber.New()
+	api := app.Group("/api/v1", authenticator.Required())
+	api.Post("/users", middleware.RequirePermission(entity.PermissionUsersCreate), ok)
+	api.Get("/users", middleware.RequirePermission(entity.PermissionUsersRead), ok)
+	api.Put("/users/:id", middleware.RequirePermission(entity.PermissionUsersUpdate), ok)
+	api.Delete("/users/:id", middleware.RequirePermission(entity.PermissionUsersDelete), ok)
+	api.Post("/roles", middleware.RequirePermission(entity.PermissionRolesManage), ok)
+	api.Get("/oauth/clients", middleware.RequirePermission(entity.PermissionClientsManage), ok)
+	return app
+}
+
+func userWithPermissions(permissions ...string) entity.User {
+	role := entity.Role{Id: uuid.New(), Name: "custom"}
+	for _, permission := range permissions {
+		role.Permissions = append(role.Permissions, entity.Permission{Name: permission})
+	}
+	return entity.User{Id: uuid.New(), Username: "john", Roles: []entity.Role{role}}
+}
+
+func TestRequirePermissionRoutes(t *testing.T) {
+	app := newPermissionApp(tokenstore.NewMemoryStore())
+
+	for _, route := range []struct {
+		method, path, permission string
+	}{
+		{"POST", "/api/v1/users", entity.PermissionUsersCreate},
+		{"GET", "/api/v1/users", entity.PermissionUsersRead},
+		{"PUT", "/api/v1/users/" + uuid.NewString(), entity.PermissionUsersUpdate},
+		{"DELETE", "/api/v1/users/" + uuid.NewString(), entity.PermissionUsersDelete},
+		{"POST", "/api/v1/roles", entity.PermissionRolesManage},
+		{"GET", "/api/v1/oauth/clients", entity.PermissionClientsManage},
+	} {
+		status := func(user *entity.User) int {
+			req := httptest.NewRequest(route.method, route.path, nil)
+			if user != nil {
+				token, err := helper.GenerateAccessToken(*user, uuid.NewString(), "", "")
+				assert.NoError(t, err)
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			return resp.StatusCode
+		}
+
+		// a role granting everything but the route's permission
+		var others []string
+		for _, permission := range entity.DefaultPermissions {
+			if permission.Name != route.permission {
+				others = append(others, permission.Name)
+			}
+		}
+		without := userWithPermissions(others...)
+		with := userWithPermissions(route.permission)
+
+		assert.Equal(t, 401, status(nil), route.method+" "+route.path)
+		assert.Equal(t, 403, status(&without), route.method+" "+route.path)
+		assert.Equal(t, 200, status(&with), route.method+" "+route.path)
+	}
+}
+
+func TestRequirePermissionWithoutPrincipal(t *testing.T) {
+	app := fiber.New()
+	app.Get("/users", middleware.RequirePermission(entity.PermissionUsersRead), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/users", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+}
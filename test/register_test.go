@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/repository"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/mailer"
+	"github.com/fatihrizqon/go-fiber-service/password"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func (m *StubAuthRepository) Register(user entity.User) (entity.User, error) {
+	user.Id = uuid.New()
+	user.CreatedAt = time.Now()
+	m.users[user.Id] = user
+	return user, nil
+}
+
+func (m *StubAuthRepository) ExistsByUsername(username string) (bool, error) {
+	for _, user := range m.users {
+		if user.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *StubAuthRepository) ExistsByEmail(email string) (bool, error) {
+	_, err := m.FindByEmail(email)
+	return err == nil, nil
+}
+
+func (m *StubAuthRepository) FindByEmail(email string) (entity.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return entity.User{}, gorm.ErrRecordNotFound
+}
+
+func (m *StubAuthRepository) MarkEmailVerified(id uuid.UUID, verifiedAt time.Time) error {
+	user := m.users[id]
+	user.EmailVerifiedAt = &verifiedAt
+	m.users[id] = user
+	return nil
+}
+
+func (m *StubAuthRepository) UpdatePassword(id uuid.UUID, hashed string) error {
+	user := m.users[id]
+	user.Password = hashed
+	m.users[id] = user
+	return nil
+}
+
+// RacingAuthRepository loses every registration to a concurrent one.
+type RacingAuthRepository struct {
+	*StubAuthRepository
+	err error
+}
+
+func (m *RacingAuthRepository) Register(user entity.User) (entity.User, error) {
+	return user, m.err
+}
+
+// newAuthService returns an AuthService on the stub repositories, with a
+// fast bcrypt hasher and the mail handed to the returned channel. The
+// channel is unbuffered, mail sent while the request waits would block it.
+func newAuthService(authRepo repository.IAuthRepository, resetRepo repository.IPasswordResetRepository, sessions service.ISessionService) (service.IAuthService, chan mailer.Message) {
+	hasher, _ := password.New(password.Config{Algorithm: "bcrypt", Bcrypt: password.BcryptParams{Cost: 4}})
+	policy := service.NewPasswordPolicyService(nil, password.NewPolicy(password.PolicyConfig{}, hasher))
+	throttle := service.NewLoginThrottleService(&MemoryLoginThrottleRepository{throttles: map[string]entity.LoginThrottle{}}, nil, &RecordingSecurityEventService{}, service.ThrottleConfig{PruneInterval: -1})
+	mail := &ChannelMailer{messages: make(chan mailer.Message)}
+
+	return service.NewAuthService(authRepo, resetRepo, sessions, throttle, service.NewLoginEventService(&MemoryLoginEventRepository{}, nil),
+		service.NewAccountMailer(mail, "http://localhost"), hasher, policy, service.AuthConfig{}, validator.New()), mail.messages
+}
+
+func TestRegister(t *testing.T) {
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{}}
+	auth, mail := newAuthService(authRepo, nil, nil)
+	ctx := context.Background()
+
+	result, err := auth.Register(ctx, request.RegisterRequest{Username: "JohnDoe", Name: "John", Email: "John@Example.com", Password: "Tr0ub4dor&3x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "johndoe", result.Username)
+	assert.Equal(t, "john@example.com", result.Email)
+	assert.NotEqual(t, "Tr0ub4dor&3x", authRepo.users[result.Id].Password)
+
+	select {
+	case message := <-mail:
+		assert.Equal(t, "john@example.com", message.To)
+	case <-time.After(time.Second):
+		t.Fatal("no verification email sent")
+	}
+
+	// names and addresses are unique regardless of case
+	_, err = auth.Register(ctx, request.RegisterRequest{Username: "johndoe", Name: "Other", Email: "other@example.com", Password: "Tr0ub4dor&3x"})
+	assert.ErrorIs(t, err, service.ErrUsernameTaken)
+	_, err = auth.Register(ctx, request.RegisterRequest{Username: "other", Name: "Other", Email: "JOHN@example.com", Password: "Tr0ub4dor&3x"})
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+
+	var policyErr *password.PolicyError
+	_, err = auth.Register(ctx, request.RegisterRequest{Username: "short", Name: "Short", Email: "short@example.com", Password: "short"})
+	assert.ErrorAs(t, err, &policyErr)
+	assert.Len(t, authRepo.users, 1)
+}
+
+func TestRegisterRace(t *testing.T) {
+	for err, expected := range map[error]error{
+		repository.ErrDuplicateUsername: service.ErrUsernameTaken,
+		repository.ErrDuplicateEmail:    service.ErrEmailTaken,
+	} {
+		// both checks pass, then the insert loses against a concurrent
+		// registration
+		authRepo := &RacingAuthRepository{StubAuthRepository: &StubAuthRepository{users: map[uuid.UUID]entity.User{}}, err: err}
+		auth, _ := newAuthService(authRepo, nil, nil)
+
+		_, regErr := auth.Register(context.Background(), request.RegisterRequest{Username: "john", Name: "John", Email: "john@example.com", Password: "Tr0ub4dor&3x"})
+		assert.ErrorIs(t, regErr, expected, err.Error())
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/mailer"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type MemoryPasswordResetRepository struct {
+	resets map[uuid.UUID]entity.PasswordReset
+}
+
+func (m *MemoryPasswordResetRepository) Create(reset entity.PasswordReset) (entity.PasswordReset, error) {
+	reset.Id = uuid.New()
+	m.resets[reset.Id] = reset
+	return reset, nil
+}
+
+func (m *MemoryPasswordResetRepository) FindByTokenHash(tokenHash string) (entity.PasswordReset, error) {
+	for _, reset := range m.resets {
+		if reset.TokenHash == tokenHash {
+			return reset, nil
+		}
+	}
+	return entity.PasswordReset{}, errors.New("record not found")
+}
+
+func (m *MemoryPasswordResetRepository) Consume(id uuid.UUID, usedAt time.Time) (bool, error) {
+	reset, ok := m.resets[id]
+	if !ok || reset.UsedAt != nil {
+		return false, nil
+	}
+	reset.UsedAt = &usedAt
+	m.resets[id] = reset
+	return true, nil
+}
+
+func (m *MemoryPasswordResetRepository) DeleteByUserId(userId uuid.UUID) error {
+	for id, reset := range m.resets {
+		if reset.UserId == userId {
+			delete(m.resets, id)
+		}
+	}
+	return nil
+}
+
+var linkToken = regexp.MustCompile(`\?token=(\S+)`)
+
+// receiveToken waits for the next mail and returns the token of its link.
+func receiveToken(t *testing.T, mail chan mailer.Message) string {
+	select {
+	case message := <-mail:
+		match := linkToken.FindStringSubmatch(message.Body)
+		if match == nil {
+			t.Fatalf("no link in %q", message.Body)
+		}
+		token, err := url.QueryUnescape(match[1])
+		assert.NoError(t, err)
+		return token
+	case <-time.After(time.Second):
+		t.Fatal("no email sent")
+		return ""
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "john", Email: "john@example.com", Password: "old-hash"}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}
+	resetRepo := &MemoryPasswordResetRepository{resets: map[uuid.UUID]entity.PasswordReset{}}
+	store := tokenstore.NewMemoryStore()
+	sessionRepo := &MemorySessionRepository{sessions: map[uuid.UUID]entity.Session{}}
+	auth, mail := newAuthService(authRepo, resetRepo, service.NewSessionService(sessionRepo, store))
+	ctx := context.Background()
+
+	session, _ := sessionRepo.Create(entity.Session{UserId: user.Id, ExpiresAt: time.Now().Add(time.Hour)})
+	issuedAt := time.Now().Add(-time.Second)
+
+	// unknown addresses look the same and send nothing
+	assert.NoError(t, auth.ForgotPassword(request.ForgotPasswordRequest{Email: "nobody@example.com"}))
+	assert.NoError(t, auth.ForgotPassword(request.ForgotPasswordRequest{Email: "john@example.com"}))
+	first := receiveToken(t, mail)
+
+	// a newer link replaces the previous one
+	assert.NoError(t, auth.ForgotPassword(request.ForgotPasswordRequest{Email: "john@example.com"}))
+	token := receiveToken(t, mail)
+	assert.ErrorIs(t, auth.ResetPassword(ctx, request.ResetPasswordRequest{Token: first, Password: "Tr0ub4dor&3x"}), service.ErrInvalidResetToken)
+
+	assert.NoError(t, auth.ResetPassword(ctx, request.ResetPasswordRequest{Token: token, Password: "Tr0ub4dor&3x"}))
+	assert.NotEqual(t, "old-hash", authRepo.users[user.Id].Password)
+
+	// the link works once
+	assert.ErrorIs(t, auth.ResetPassword(ctx, request.ResetPasswordRequest{Token: token, Password: "An0ther&Passw0rd"}), service.ErrInvalidResetToken)
+
+	// and every session of the user is signed out
+	assert.NotNil(t, sessionRepo.sessions[session.Id].RevokedAt)
+	revoked, err := store.IsUserRevoked(user.Id.String(), issuedAt)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestResetPasswordExpired(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "john", Email: "john@example.com"}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}
+	resetRepo := &MemoryPasswordResetRepository{resets: map[uuid.UUID]entity.PasswordReset{}}
+	auth, mail := newAuthService(authRepo, resetRepo, nil)
+
+	assert.NoError(t, auth.ForgotPassword(request.ForgotPasswordRequest{Email: "john@example.com"}))
+	token := receiveToken(t, mail)
+	for id, reset := range resetRepo.resets {
+		reset.ExpiresAt = time.Now().Add(-time.Minute)
+		resetRepo.resets[id] = reset
+	}
+
+	assert.ErrorIs(t, auth.ResetPassword(context.Background(), request.ResetPasswordRequest{Token: token, Password: "Tr0ub4dor&3x"}), service.ErrInvalidResetToken)
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type MemorySessionRepository struct {
+	sessions map[uuid.UUID]entity.Session
+}
+
+func (m *MemorySessionRepository) Create(session entity.Session) (entity.Session, error) {
+	session.Id = uuid.New()
+	session.CreatedAt = time.Now()
+	m.sessions[session.Id] = session
+	return session, nil
+}
+
+func (m *MemorySessionRepository) FindById(sessionId uuid.UUID) (entity.Session, error) {
+	session, ok := m.sessions[sessionId]
+	if !ok {
+		return session, errors.New("record not found")
+	}
+	return session, nil
+}
+
+func (m *MemorySessionRepository) FindActiveByUserId(userId uuid.UUID) ([]entity.Session, error) {
+	var sessions []entity.Session
+	for _, session := range m.sessions {
+		if session.UserId == userId && session.IsActive() {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (m *MemorySessionRepository) Touch(sessionId uuid.UUID, seenAt, expiresAt time.Time) error {
+	session := m.sessions[sessionId]
+	session.LastSeenAt = seenAt
+	session.ExpiresAt = expiresAt
+	m.sessions[sessionId] = session
+	return nil
+}
+
+func (m *MemorySessionRepository) Revoke(sessionId uuid.UUID, revokedAt time.Time) error {
+	session, ok := m.sessions[sessionId]
+	if ok && session.RevokedAt == nil {
+		session.RevokedAt = &revokedAt
+		m.sessions[sessionId] = session
+	}
+	return nil
+}
+
+func (m *MemorySessionRepository) RevokeAllByUserId(userId uuid.UUID, revokedAt time.Time) error {
+	for id, session := range m.sessions {
+		if session.UserId == userId && session.RevokedAt == nil {
+			session.RevokedAt = &revokedAt
+			m.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func TestSessionList(t *testing.T) {
+	userId, otherId := uuid.New(), uuid.New()
+	sessionRepo := &MemorySessionRepository{sessions: map[uuid.UUID]entity.Session{}}
+	sessions := service.NewSessionService(sessionRepo, tokenstore.NewMemoryStore())
+
+	phone, _ := sessions.Start(userId, service.ClientInfo{IP: "10.0.0.1", UserAgent: "phone"})
+	laptop, _ := sessions.Start(userId, service.ClientInfo{IP: "10.0.0.2", UserAgent: "laptop"})
+	revoked, _ := sessions.Start(userId, service.ClientInfo{IP: "10.0.0.3", UserAgent: "old"})
+	_, _ = sessions.Start(otherId, service.ClientInfo{IP: "10.0.0.4"})
+	assert.NoError(t, sessions.Revoke(userId, revoked.Id))
+	assert.NoError(t, sessionRepo.Touch(phone.Id, time.Now().Add(time.Minute), time.Now().Add(time.Hour)))
+
+	// only the user's active sessions, most recently used first
+	list, err := sessions.FindAllByUserId(userId, laptop.Id)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, phone.Id, list[0].Id)
+	assert.False(t, list[0].Current)
+	assert.Equal(t, laptop.Id, list[1].Id)
+	assert.True(t, list[1].Current)
+	assert.Equal(t, "laptop", list[1].UserAgent)
+}
+
+func TestSessionRevoke(t *testing.T) {
+	userId, otherId := uuid.New(), uuid.New()
+	store := tokenstore.NewMemoryStore()
+	sessionRepo := &MemorySessionRepository{sessions: map[uuid.UUID]entity.Session{}}
+	sessions := service.NewSessionService(sessionRepo, store)
+
+	session, _ := sessions.Start(userId, service.ClientInfo{IP: "10.0.0.1"})
+
+	// nobody else can sign the session out
+	assert.ErrorIs(t, sessions.Revoke(otherId, session.Id), service.ErrSessionNotFound)
+	assert.ErrorIs(t, sessions.Revoke(userId, uuid.New()), service.ErrSessionNotFound)
+	assert.NoError(t, sessions.Touch(session.Id))
+
+	assert.NoError(t, sessions.Revoke(userId, session.Id))
+	assert.NotNil(t, sessionRepo.sessions[session.Id].RevokedAt)
+	assert.ErrorIs(t, sessions.Touch(session.Id), service.ErrSessionRevoked)
+
+	// tokens of the session stop working at once
+	revoked, err := tokenstore.IsTokenRevoked(store, uuid.NewString(), userId.String(), session.Id.String(), time.Now())
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTokenService(user entity.User) (service.ITokenService, service.ISessionService, *MemorySessionRepository, *RecordingSecurityEventService) {
+	store := tokenstore.NewMemoryStore()
+	sessionRepo := &MemorySessionRepository{sessions: map[uuid.UUID]entity.Session{}}
+	sessions := service.NewSessionService(sessionRepo, store)
+	events := &RecordingSecurityEventService{}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}
+	return service.NewTokenService(authRepo, sessions, events, store), sessions, sessionRepo, events
+}
+
+func TestTokenRotate(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "john"}
+	tokens, _, sessionRepo, _ := newTokenService(user)
+	client := service.ClientInfo{IP: "10.0.0.1"}
+
+	pair, err := tokens.Issue(user, client)
+	assert.NoError(t, err)
+	assert.Len(t, sessionRepo.sessions, 1)
+
+	rotated, err := tokens.Rotate(pair.RefreshToken, client)
+	assert.NoError(t, err)
+	assert.NotEqual(t, pair.RefreshToken, rotated.RefreshToken)
+	assert.NotEmpty(t, rotated.AccessToken)
+
+	// the new token rotates again, still within the one session
+	_, err = tokens.Rotate(rotated.RefreshToken, client)
+	assert.NoError(t, err)
+	assert.Len(t, sessionRepo.sessions, 1)
+
+	_, err = tokens.Rotate("not-a-token", client)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestTokenRotateReuse(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "john"}
+	tokens, _, sessionRepo, events := newTokenService(user)
+	client := service.ClientInfo{IP: "10.0.0.1"}
+
+	pair, err := tokens.Issue(user, client)
+	assert.NoError(t, err)
+	rotated, err := tokens.Rotate(pair.RefreshToken, client)
+	assert.NoError(t, err)
+
+	// replaying the rotated token revokes the whole family
+	_, err = tokens.Rotate(pair.RefreshToken, client)
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+	assert.Equal(t, []string{entity.SecurityEventRefreshTokenReuse}, events.events)
+	for _, session := range sessionRepo.sessions {
+		assert.NotNil(t, session.RevokedAt)
+	}
+
+	// including the token the legitimate client holds
+	_, err = tokens.Rotate(rotated.RefreshToken, client)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestTokenRotateRevokedFamily(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "john"}
+	tokens, sessions, sessionRepo, events := newTokenService(user)
+	client := service.ClientInfo{IP: "10.0.0.1"}
+
+	pair, err := tokens.Issue(user, client)
+	assert.NoError(t, err)
+
+	for id := range sessionRepo.sessions {
+		assert.NoError(t, sessions.Revoke(user.Id, id))
+	}
+
+	_, err = tokens.Rotate(pair.RefreshToken, client)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	// a signed out session is no reuse
+	assert.Empty(t, events.events)
+}
+
+func TestTokenRotateWrongClient(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "john"}
+	tokens, _, _, _ := newTokenService(user)
+
+	pair, err := tokens.Issue(user, service.ClientInfo{IP: "10.0.0.1", OAuthClientId: "app", Scope: "openid"})
+	assert.NoError(t, err)
+
+	_, err = tokens.Rotate(pair.RefreshToken, service.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	_, err = tokens.Rotate(pair.RefreshToken, service.ClientInfo{IP: "10.0.0.1", OAuthClientId: "other"})
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	// refused attempts do not use the token up
+	rotated, err := tokens.Rotate(pair.RefreshToken, service.ClientInfo{IP: "10.0.0.1", OAuthClientId: "app"})
+	assert.NoError(t, err)
+	assert.Equal(t, "openid", rotated.Scope)
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/tokenstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreRevoke(t *testing.T) {
+	store := tokenstore.NewMemoryStore()
+	jti := uuid.NewString()
+
+	revoked, err := store.IsRevoked(jti)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	assert.NoError(t, store.Revoke(jti, time.Now().Add(time.Minute)))
+
+	revoked, err = store.IsRevoked(jti)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	store := tokenstore.NewMemoryStore()
+	jti := uuid.NewString()
+
+	assert.NoError(t, store.Revoke(jti, time.Now().Add(-time.Second)))
+
+	revoked, err := store.IsRevoked(jti)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestMemoryStoreRevokeUser(t *testing.T) {
+	store := tokenstore.NewMemoryStore()
+	userId := uuid.NewString()
+	issuedBefore := time.Now().Add(-time.Minute)
+
+	assert.NoError(t, store.RevokeUser(userId, time.Now().Add(time.Hour)))
+
+	revoked, err := store.IsUserRevoked(userId, issuedBefore)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = store.IsUserRevoked(userId, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestMemoryStoreRevokeUserSameSecond(t *testing.T) {
+	store := tokenstore.NewMemoryStore()
+	user := entity.User{Id: uuid.New(), Username: "john"}
+
+	before, err := helper.GenerateAccessToken(user, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	assert.NoError(t, store.RevokeUser(user.Id.String(), time.Now().Add(time.Hour)))
+	time.Sleep(5 * time.Millisecond)
+	// signing in again right away, most likely within the same second
+	after, err := helper.GenerateAccessToken(user, uuid.NewString(), "", "")
+	assert.NoError(t, err)
+
+	for token, expected := range map[string]bool{before: true, after: false} {
+		claims, err := helper.ParseToken(token, false)
+		assert.NoError(t, err)
+		revoked, err := store.IsUserRevoked(user.Id.String(), claims.IssuedAt.Time)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, revoked)
+	}
+}
+
+func TestMemoryStoreConsume(t *testing.T) {
+	store := tokenstore.NewMemoryStore()
+	jti := uuid.NewString()
+
+	consumed, err := store.Consume(jti, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+
+	consumed, err = store.Consume(jti, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, consumed)
+
+	revoked, err := store.IsRevoked(jti)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestMemoryStoreConsumeExpired(t *testing.T) {
+	store := tokenstore.NewMemoryStore()
+	jti := uuid.NewString()
+
+	// an entry that expired but has not been swept does not count as used
+	assert.NoError(t, store.Revoke(jti, time.Now().Add(-time.Second)))
+
+	consumed, err := store.Consume(jti, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 6238 test vectors, truncated to six digits.
+func TestTOTPCode(t *testing.T) {
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	code, err := helper.TOTPCode(secret, time.Unix(59, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	code, err = helper.TOTPCode(secret, time.Unix(1111111109, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "081804", code)
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := helper.GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	now := time.Now()
+	code, err := helper.TOTPCode(secret, now.Add(-30*time.Second))
+	assert.NoError(t, err)
+
+	step, ok := helper.ValidateTOTP(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30-1, step)
+
+	code, err = helper.TOTPCode(secret, now.Add(-2*time.Minute))
+	assert.NoError(t, err)
+
+	_, ok = helper.ValidateTOTP(secret, code, now)
+	assert.False(t, ok)
+}
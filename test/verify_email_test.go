@@ -0,0 +1,64 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/helper"
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/internal/presenter/request"
+	"github.com/fatihrizqon/go-fiber-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	user := entity.User{Id: uuid.New(), Username: "john", Email: "john@example.com"}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{user.Id: user}}
+	auth, _ := newAuthService(authRepo, nil, nil)
+
+	token, err := helper.GenerateEmailVerificationToken(user)
+	assert.NoError(t, err)
+
+	// the token is bound to the address it was sent to
+	changed := user
+	changed.Email = "someone-else@example.com"
+	authRepo.users[user.Id] = changed
+	assert.ErrorIs(t, auth.VerifyEmail(request.VerifyEmailRequest{Token: token}), service.ErrInvalidVerificationToken)
+	assert.Nil(t, authRepo.users[user.Id].EmailVerifiedAt)
+
+	authRepo.users[user.Id] = user
+	assert.NoError(t, auth.VerifyEmail(request.VerifyEmailRequest{Token: token}))
+	assert.NotNil(t, authRepo.users[user.Id].EmailVerifiedAt)
+
+	// tokens of another purpose are refused
+	challenge, err := helper.GenerateMfaChallengeToken(user)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, auth.VerifyEmail(request.VerifyEmailRequest{Token: challenge}), service.ErrInvalidVerificationToken)
+	assert.ErrorIs(t, auth.VerifyEmail(request.VerifyEmailRequest{Token: "not-a-token"}), service.ErrInvalidVerificationToken)
+}
+
+func TestResendVerification(t *testing.T) {
+	verifiedAt := time.Now()
+	unverified := entity.User{Id: uuid.New(), Username: "john", Email: "john@example.com"}
+	verified := entity.User{Id: uuid.New(), Username: "jane", Email: "jane@example.com", EmailVerifiedAt: &verifiedAt}
+	authRepo := &StubAuthRepository{users: map[uuid.UUID]entity.User{unverified.Id: unverified, verified.Id: verified}}
+	auth, mail := newAuthService(authRepo, nil, nil)
+
+	// every address gets the same answer, and nobody waits for the mail
+	for _, email := range []string{"john@example.com", "jane@example.com", "nobody@example.com"} {
+		assert.NoError(t, auth.ResendVerification(request.ResendVerificationRequest{Email: email}))
+	}
+
+	select {
+	case message := <-mail:
+		assert.Equal(t, "john@example.com", message.To)
+	case <-time.After(time.Second):
+		t.Fatal("no verification email sent")
+	}
+	select {
+	case message := <-mail:
+		t.Fatalf("unexpected email to %s", message.To)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
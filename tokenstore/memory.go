@@ -0,0 +1,69 @@
+package tokenstore
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// memoryBackend keeps entries in a process-local map. Revocations are lost
+// on restart and not shared between replicas, use it for development and
+// single instance deployments only.
+type memoryBackend struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryStore() TokenStore {
+	b := &memoryBackend{entries: make(map[string]memoryEntry)}
+	go b.sweep(time.Minute)
+	return &store{backend: b}
+}
+
+func (b *memoryBackend) set(key string, value int64, expiresAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[key] = memoryEntry{value: value, expiresAt: expiresAt}
+	return nil
+}
+
+func (b *memoryBackend) add(key string, value int64, expiresAt time.Time) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if entry, ok := b.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+		return false, nil
+	}
+	b.entries[key] = memoryEntry{value: value, expiresAt: expiresAt}
+	return true, nil
+}
+
+func (b *memoryBackend) get(key string) (int64, bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	entry, ok := b.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// sweep periodically drops expired entries so the map does not grow forever.
+func (b *memoryBackend) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		b.mu.Lock()
+		for key, entry := range b.entries {
+			if now.After(entry.expiresAt) {
+				delete(b.entries, key)
+			}
+		}
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,77 @@
+package tokenstore
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fatihrizqon/go-fiber-service/internal/entity"
+	"github.com/fatihrizqon/go-fiber-service/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// postgresBackend stores entries in the revoked_tokens table, shared by all
+// replicas using the same database.
+type postgresBackend struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) TokenStore {
+	b := &postgresBackend{db: db}
+	go b.sweep(10 * time.Minute)
+	return &store{backend: b}
+}
+
+func (b *postgresBackend) set(key string, value int64, expiresAt time.Time) error {
+	return b.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires_at"}),
+	}).Create(&entity.RevokedToken{
+		Key:       key,
+		Value:     value,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// add takes over a row that has expired but not been swept yet.
+func (b *postgresBackend) add(key string, value int64, expiresAt time.Time) (bool, error) {
+	result := b.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "revoked_tokens.expires_at <= ?", Vars: []interface{}{time.Now()}},
+		}},
+	}).Create(&entity.RevokedToken{
+		Key:       key,
+		Value:     value,
+		ExpiresAt: expiresAt,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (b *postgresBackend) get(key string) (int64, bool, error) {
+	var entry entity.RevokedToken
+	err := b.db.Where("key = ? AND expires_at > ?", key, time.Now()).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return entry.Value, true, nil
+}
+
+// sweep periodically deletes expired rows.
+func (b *postgresBackend) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := b.db.Where("expires_at <= ?", time.Now()).Delete(&entity.RevokedToken{}).Error; err != nil {
+			logger.GetLogger().WithError(err).Error("failed to purge expired revoked tokens")
+		}
+	}
+}
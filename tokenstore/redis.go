@@ -0,0 +1,65 @@
+package tokenstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "revoked:"
+
+// redisBackend stores entries in any Redis protocol compatible server and
+// lets the server expire them.
+type redisBackend struct {
+	client redis.UniversalClient
+}
+
+// NewRedisStore connects to the server described by a redis:// or
+// rediss:// URL.
+func NewRedisStore(url string) (TokenStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return &store{backend: &redisBackend{client: redis.NewClient(options)}}, nil
+}
+
+func (b *redisBackend) set(key string, value int64, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	return b.client.SetArgs(ctx, redisKeyPrefix+key, strconv.FormatInt(value, 10), redis.SetArgs{ExpireAt: expiresAt}).Err()
+}
+
+func (b *redisBackend) add(key string, value int64, expiresAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := b.client.SetArgs(ctx, redisKeyPrefix+key, strconv.FormatInt(value, 10), redis.SetArgs{Mode: "NX", ExpireAt: expiresAt}).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *redisBackend) get(key string) (int64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	raw, err := b.client.Get(ctx, redisKeyPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return value, true, nil
+}
@@ -0,0 +1,125 @@
+package tokenstore
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenStore keeps track of revoked tokens. Entries are keyed by token ID
+// (jti) and disappear once the token would have expired anyway.
+type TokenStore interface {
+	// Revoke marks the token with the given ID as revoked until expiresAt.
+	Revoke(jti string, expiresAt time.Time) error
+	// IsRevoked reports whether the token with the given ID was revoked.
+	IsRevoked(jti string) (bool, error)
+	// Consume revokes the token like Revoke, reporting false if it had
+	// already been revoked. It is atomic, so of several concurrent calls
+	// for the same token only one succeeds.
+	Consume(jti string, expiresAt time.Time) (bool, error)
+	// RevokeFamily revokes every token of a refresh token family.
+	RevokeFamily(family string, expiresAt time.Time) error
+	// IsFamilyRevoked reports whether the refresh token family was revoked.
+	IsFamilyRevoked(family string) (bool, error)
+	// RevokeUser revokes every token issued to the user until now. The
+	// marker is kept until expiresAt, by then all such tokens are expired.
+	RevokeUser(userId string, expiresAt time.Time) error
+	// IsUserRevoked reports whether a token issued to the user at issuedAt
+	// was revoked by RevokeUser.
+	IsUserRevoked(userId string, issuedAt time.Time) (bool, error)
+}
+
+// IsTokenRevoked runs every revocation check for one token: its own id,
+// the user it was issued to and its session family. userId and family are
+// skipped when empty.
+func IsTokenRevoked(s TokenStore, jti, userId, family string, issuedAt time.Time) (bool, error) {
+	revoked, err := s.IsRevoked(jti)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	if userId != "" {
+		revoked, err = s.IsUserRevoked(userId, issuedAt)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	if family != "" {
+		return s.IsFamilyRevoked(family)
+	}
+
+	return false, nil
+}
+
+// Config selects and configures the store backend.
+type Config struct {
+	Driver   string
+	Db       *gorm.DB
+	RedisURL string
+}
+
+// New returns the TokenStore for the configured driver, defaulting to the
+// in-memory store.
+func New(config Config) (TokenStore, error) {
+	switch config.Driver {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		return NewPostgresStore(config.Db), nil
+	case "redis":
+		return NewRedisStore(config.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown token store driver: %s", config.Driver)
+	}
+}
+
+// backend is a key/value store with per-entry expiry. Values are unix
+// timestamps in milliseconds of when the entry was revoked.
+type backend interface {
+	set(key string, value int64, expiresAt time.Time) error
+	// add sets the entry only if the key is not present yet.
+	add(key string, value int64, expiresAt time.Time) (bool, error)
+	get(key string) (int64, bool, error)
+}
+
+type store struct {
+	backend backend
+}
+
+func (s *store) Revoke(jti string, expiresAt time.Time) error {
+	return s.backend.set("jti:"+jti, time.Now().UnixMilli(), expiresAt)
+}
+
+func (s *store) IsRevoked(jti string) (bool, error) {
+	_, found, err := s.backend.get("jti:" + jti)
+	return found, err
+}
+
+func (s *store) Consume(jti string, expiresAt time.Time) (bool, error) {
+	return s.backend.add("jti:"+jti, time.Now().UnixMilli(), expiresAt)
+}
+
+func (s *store) RevokeFamily(family string, expiresAt time.Time) error {
+	return s.backend.set("family:"+family, time.Now().UnixMilli(), expiresAt)
+}
+
+func (s *store) IsFamilyRevoked(family string) (bool, error) {
+	_, found, err := s.backend.get("family:" + family)
+	return found, err
+}
+
+func (s *store) RevokeUser(userId string, expiresAt time.Time) error {
+	return s.backend.set("user:"+userId, time.Now().UnixMilli(), expiresAt)
+}
+
+func (s *store) IsUserRevoked(userId string, issuedAt time.Time) (bool, error) {
+	revokedAt, found, err := s.backend.get("user:" + userId)
+	if err != nil || !found {
+		return false, err
+	}
+	// iat has a resolution of one millisecond, so a token issued within
+	// the millisecond of the revocation is treated as revoked as well
+	return issuedAt.UnixMilli() <= revokedAt, nil
+}